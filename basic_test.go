@@ -1,778 +1,671 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/ur-wesley/commentRemover/remover"
 )
 
-func TestGetLanguageByExtension(t *testing.T) {
-	tests := []struct {
-		filename     string
-		expectedLang string
-		supported    bool
-	}{
-		{
-			filename:     "main.go",
-			expectedLang: "Go",
-			supported:    true,
-		},
-		{
-			filename:     "script.js",
-			expectedLang: "TypeScript/JavaScript",
-			supported:    true,
-		},
-		{
-			filename:     "component.tsx",
-			expectedLang: "TypeScript/JavaScript",
-			supported:    true,
-		},
-		{
-			filename:     "query.sql",
-			expectedLang: "SQL",
-			supported:    true,
-		},
-		{
-			filename:     "config.json",
-			expectedLang: "JSON",
-			supported:    true,
-		},
-		{
-			filename:     "script.php",
-			expectedLang: "PHP",
-			supported:    true,
-		},
-		{
-			filename:     "template.phtml",
-			expectedLang: "PHP",
-			supported:    true,
-		},
-		{
-			filename:     "Program.cs",
-			expectedLang: "C#",
-			supported:    true,
-		},
-		{
-			filename:     "README.md",
-			expectedLang: "",
-			supported:    false,
-		},
-		{
-			filename:     "no_extension",
-			expectedLang: "",
-			supported:    false,
-		},
+func TestProcessFile_LongLinesAndMinified(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_minified_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
+	defer os.RemoveAll(tempDir)
 
-	for _, tt := range tests {
-		t.Run(tt.filename, func(t *testing.T) {
-			lang, supported := GetLanguageByExtension(tt.filename)
+	bundle := filepath.Join(tempDir, "bundle.min.js")
+	longLine := "const a = \"" + strings.Repeat("x", 11<<20) + "\"; // trailing"
+	if err := os.WriteFile(bundle, []byte(longLine), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	normal := filepath.Join(tempDir, "app.js")
+	if err := os.WriteFile(normal, []byte(strings.Repeat("run(); // call\n", 500)), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
 
-			if supported != tt.supported {
-				t.Errorf("Expected supported=%v, got %v", tt.supported, supported)
-			}
+	lang, _ := remover.LanguageByKey("typescript")
+	if _, err := remover.ProcessFile(bundle, lang, remover.Options{SkipMinified: true}); !errors.Is(err, remover.ErrMinified) {
+		t.Errorf("Expected ErrMinified, got %v", err)
+	}
 
-			if tt.supported {
-				if lang == nil {
-					t.Fatal("Expected language object, got nil")
-				}
-				if lang.Name != tt.expectedLang {
-					t.Errorf("Expected language %q, got %q", tt.expectedLang, lang.Name)
-				}
-			} else {
-				if lang != nil {
-					t.Errorf("Expected nil language for unsupported file, got %v", lang)
-				}
-			}
-		})
+	result, err := remover.ProcessFile(bundle, lang, remover.Options{})
+	if err != nil {
+		t.Fatalf("ProcessFile failed on an 11 MB line: %v", err)
+	}
+	if result.CommentsRemoved != 1 || !strings.HasSuffix(string(result.Content()), "\";") {
+		t.Errorf("Expected the trailing comment to be removed from the long line")
 	}
-}
 
-func TestRemoveSingleLineComment(t *testing.T) {
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+	files := []remover.FileInfo{{Path: bundle, Language: lang}, {Path: normal, Language: lang}}
+	stats := ProcessMultipleFiles(files, ProcessingOptions{Format: FormatJSON}, 0)
+	if stats.FilesProcessed != 1 || stats.FilesSkipped != 1 || len(stats.Errors) != 0 {
+		t.Errorf("Expected 1 processed and 1 skipped file without errors, got %+v", stats)
 	}
+	if len(stats.Skipped) != 1 || stats.Skipped[0].Path != bundle || !strings.Contains(stats.Skipped[0].Reason, "single line") {
+		t.Errorf("Expected minified skip reason, got %+v", stats.Skipped)
+	}
+}
 
+func TestFormatUnifiedDiff(t *testing.T) {
 	tests := []struct {
-		name               string
-		input              string
-		inMultiLineComment bool
-		expectedOutput     string
-		expectedRemoved    bool
+		name     string
+		original string
+		expected string
 	}{
 		{
-			name:               "standalone comment",
-			input:              "// This is a comment",
-			inMultiLineComment: false,
-			expectedOutput:     "REMOVE_LINE",
-			expectedRemoved:    true,
+			name:     "no changes",
+			original: "a\nb\n",
+			expected: "",
 		},
 		{
-			name:               "inline comment",
-			input:              `fmt.Println("Hello") // This is a comment`,
-			inMultiLineComment: false,
-			expectedOutput:     `fmt.Println("Hello")`,
-			expectedRemoved:    true,
+			name:     "removed and changed lines",
+			original: "a\n// b\nc // d\ne\n",
+			expected: "--- a/src/x.ts\n+++ b/src/x.ts\n@@ -1,4 +1,3 @@\n a\n-// b\n-c // d\n+c\n e\n",
 		},
 		{
-			name:               "no comment",
-			input:              `fmt.Println("Hello")`,
-			inMultiLineComment: false,
-			expectedOutput:     `fmt.Println("Hello")`,
-			expectedRemoved:    false,
+			name:     "separate hunks",
+			original: "1\n// x\n3\n4\n5\n6\n7\n8\n9\n10\n// y\n",
+			expected: "--- a/src/x.ts\n+++ b/src/x.ts\n@@ -1,5 +1,4 @@\n 1\n-// x\n 3\n 4\n 5\n@@ -8,4 +7,3 @@\n 8\n 9\n 10\n-// y\n",
 		},
 		{
-			name:               "comment in string literal",
-			input:              `fmt.Println("Hello // World")`,
-			inMultiLineComment: false,
-			expectedOutput:     `fmt.Println("Hello // World")`,
-			expectedRemoved:    false,
+			name:     "missing trailing newline and crlf",
+			original: "a\r\n// b",
+			expected: "--- a/src/x.ts\n+++ b/src/x.ts\n@@ -1,2 +1 @@\n-a\r\n-// b\n\\ No newline at end of file\n+a\n\\ No newline at end of file\n",
 		},
 		{
-			name:               "inside multi-line comment",
-			input:              "// This comment is inside a multi-line block",
-			inMultiLineComment: true,
-			expectedOutput:     "// This comment is inside a multi-line block",
-			expectedRemoved:    false,
+			name:     "leading lines removed",
+			original: "// a\n// b\nx\n",
+			expected: "--- a/src/x.ts\n+++ b/src/x.ts\n@@ -1,3 +1 @@\n-// a\n-// b\n x\n",
 		},
 	}
 
+	lang := remover.SupportedLanguages()["typescript"]
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output, removed := RemoveSingleLineComment(tt.input, lang, tt.inMultiLineComment, false, false)
-			if output != tt.expectedOutput {
-				t.Errorf("Expected output %q, got %q", tt.expectedOutput, output)
+			result, err := remover.Remove([]byte(tt.original), lang, remover.Options{Consecutive: true})
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
 			}
-			if removed != tt.expectedRemoved {
-				t.Errorf("Expected removed %v, got %v", tt.expectedRemoved, removed)
+			got, err := formatUnifiedDiff("./src/x.ts", &result, false)
+			if err != nil {
+				t.Fatalf("formatUnifiedDiff failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected diff:\n%q\ngot:\n%q", tt.expected, got)
 			}
 		})
 	}
 }
 
-func TestUpdateMultiLineCommentState(t *testing.T) {
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+func TestFormatUnifiedDiff_LargeFile(t *testing.T) {
+	const lines = 50000
+	var src strings.Builder
+	for i := range lines {
+		fmt.Fprintf(&src, "x%d := %d // note %d\n", i, i, i)
 	}
-
-	tests := []struct {
-		name         string
-		input        string
-		currentState bool
-		expected     bool
-	}{
-		{
-			name:         "start multi-line comment",
-			input:        "/* This starts a comment",
-			currentState: false,
-			expected:     true,
-		},
-		{
-			name:         "end multi-line comment",
-			input:        "This ends a comment */",
-			currentState: true,
-			expected:     false,
-		},
-		{
-			name:         "complete multi-line comment",
-			input:        "/* Complete comment */",
-			currentState: false,
-			expected:     false,
-		},
-		{
-			name:         "no comment markers",
-			input:        "Regular code line",
-			currentState: false,
-			expected:     false,
-		},
-		{
-			name:         "continue in comment",
-			input:        "Still in comment",
-			currentState: true,
-			expected:     true,
-		},
+	result, err := remover.Remove([]byte(src.String()), remover.SupportedLanguages()["go"], remover.Options{})
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := UpdateMultiLineCommentState(tt.input, lang, tt.currentState)
-			if result != tt.expected {
-				t.Errorf("Expected %v, got %v for input %q with state %v", tt.expected, result, tt.input, tt.currentState)
-			}
-		})
+	start := time.Now()
+	diff, err := formatUnifiedDiff("big.go", &result, false)
+	if err != nil {
+		t.Fatalf("formatUnifiedDiff failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Diff of %d changed lines took %v", lines, elapsed)
+	}
+	if !strings.Contains(diff, fmt.Sprintf("@@ -1,%d +1,%d @@\n", lines, lines)) {
+		t.Errorf("Expected a single hunk over all %d lines, got header %q", lines, diff[:min(len(diff), 80)])
+	}
+	if got := strings.Count(diff, "\n-x"); got != lines {
+		t.Errorf("Expected %d removed lines, got %d", lines, got)
 	}
 }
 
-func TestJSXCommentDetection(t *testing.T) {
-	lang := Language{
-		Name:            "TypeScript/JavaScript",
-		Extensions:      []string{".ts", ".tsx", ".js", ".jsx"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
-		AdditionalMultiLinePatterns: []MultiLinePattern{
-			{Start: "{/*", End: "*/}"},
-		},
+func TestFormatUnifiedDiff_InvalidLineMap(t *testing.T) {
+	result := remover.Result{
+		SourceLines:     []string{"a", "b"},
+		SourceEndings:   []string{"\n", "\n"},
+		LineMap:         []int{0, 1},
+		ModifiedLines:   []string{"a"},
+		ModifiedEndings: []string{"\n"},
 	}
-
-	tests := []struct {
-		name         string
-		input        string
-		currentState bool
-		expected     bool
-	}{
-		{
-			name:         "start JSX comment",
-			input:        "  {/* This is a JSX comment",
-			currentState: false,
-			expected:     true,
-		},
-		{
-			name:         "end JSX comment",
-			input:        "This ends a JSX comment */}",
-			currentState: true,
-			expected:     false,
-		},
-		{
-			name:         "complete JSX comment",
-			input:        "  {/* Complete JSX comment */}",
-			currentState: false,
-			expected:     false,
-		},
-		{
-			name:         "JSX comment with special chars",
-			input:        "  {/* Comment with -- // *** */}",
-			currentState: false,
-			expected:     false,
-		},
-		{
-			name:         "continue in JSX comment",
-			input:        "Still in JSX comment",
-			currentState: true,
-			expected:     true,
-		},
-		{
-			name:         "mixed JSX and regular comments",
-			input:        "  {/* JSX comment */} /* Regular comment",
-			currentState: false,
-			expected:     true,
-		},
+	if _, err := formatUnifiedDiff("x.ts", &result, false); err == nil {
+		t.Error("Expected an error for a line map pointing past the modified lines")
 	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := UpdateMultiLineCommentState(tt.input, lang, tt.currentState)
-			if result != tt.expected {
-				t.Errorf("Expected %v, got %v for input %q with state %v", tt.expected, result, tt.input, tt.currentState)
-			}
-		})
+func TestCheckMode(t *testing.T) {
+	comments := []remover.RemovedComment{
+		{LineNumber: 3, EndLineNumber: 3, Text: "// stray", Content: "  // stray"},
+		{LineNumber: 4, EndLineNumber: 4, Text: "// inline", Content: "foo(); // inline"},
+		{LineNumber: 7, EndLineNumber: 9, Text: "/* first\n second\n */", Content: "/* first\n second\n */"},
+	}
+	expected := []string{"src/a.ts:3: // stray", "src/a.ts:4: // inline", "src/a.ts:7: /* first"}
+	if got := checkFindings("src/a.ts", comments); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected findings %v, got %v", expected, got)
 	}
-}
 
-func TestIsInsideStringLiteral(t *testing.T) {
 	tests := []struct {
 		name     string
-		line     string
-		pos      int
-		expected bool
+		stats    ProcessingStats
+		expected int
 	}{
-		{
-			name:     "outside string",
-			line:     `fmt.Println("Hello") // comment`,
-			pos:      21,
-			expected: false,
-		},
-		{
-			name:     "inside double quotes",
-			line:     `fmt.Println("Hello // World")`,
-			pos:      19,
-			expected: true,
-		},
-		{
-			name:     "inside single quotes",
-			line:     `char := '//' // comment`,
-			pos:      10,
-			expected: true,
-		},
-		{
-			name:     "inside backticks",
-			line:     "msg := `Hello // World` // comment",
-			pos:      14,
-			expected: true,
-		},
+		{"clean", ProcessingStats{FilesProcessed: 2}, 0},
+		{"comments found", ProcessingStats{FilesProcessed: 2, TotalComments: 1}, 1},
+		{"errors win over findings", ProcessingStats{TotalComments: 1, Errors: []string{"a.ts: boom"}}, 2},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := IsInsideStringLiteral(tt.line, tt.pos)
-			if result != tt.expected {
-				t.Errorf("Expected %v, got %v for line %q at position %d", tt.expected, result, tt.line, tt.pos)
+			if got := checkExitCode(&tt.stats); got != tt.expected {
+				t.Errorf("Expected exit code %d, got %d", tt.expected, got)
 			}
 		})
 	}
 }
 
-func TestProcessFile(t *testing.T) {
-	content := `package main
-
-import "fmt"
-
-func main() {
-	fmt.Println("Hello")
-	/* This is a multi-line comment
-	   // This should NOT be removed
-	   End of multi-line comment */
-	fmt.Println("String with // comment inside")
-}
-`
-
-	tmpFile, err := os.CreateTemp("", "test_*.go")
+func TestJSONReport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_json_*")
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
-	defer os.Remove(tmpFile.Name())
+	defer os.RemoveAll(tempDir)
 
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
+	path := filepath.Join(tempDir, "a.ts")
+	content := "// header\nconst a = 1; // inline\n/* block\n   more */\nconst b = 2;\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
-	tmpFile.Close()
 
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
-	}
+	options := ProcessingOptions{Format: FormatJSON, RemoveBlocks: true}
+	stats := ProcessMultipleFiles([]remover.FileInfo{{Path: path, Language: remover.SupportedLanguages()["typescript"]}}, options, 0)
+	stats.Skipped = append(stats.Skipped, SkippedFile{Path: "link.ts", Reason: "symlink"})
 
-	result, err := ProcessFile(tmpFile.Name(), lang, false, false, []string{})
-	if err != nil {
-		t.Fatalf("ProcessFile failed: %v", err)
+	var buf bytes.Buffer
+	if err := writeReport(&buf, options, stats); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
 	}
 
-	if result.OriginalLines != 11 {
-		t.Errorf("Expected 11 original lines, got %d", result.OriginalLines)
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v\n%s", err, buf.String())
 	}
 
-	if result.CommentsRemoved != 0 {
-		t.Errorf("Expected 0 comments removed, got %d", result.CommentsRemoved)
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(report.Files))
 	}
-
-	expectedRemovedLines := []int{}
-	actualRemovedLines := make([]int, len(result.RemovedComments))
-	for i, comment := range result.RemovedComments {
-		actualRemovedLines[i] = comment.LineNumber
+	file := report.Files[0]
+	if file.LanguageKey != "typescript" || file.OriginalLines != 5 || file.RemainingLines != 2 {
+		t.Errorf("Unexpected file summary: %+v", file)
 	}
 
-	if !reflect.DeepEqual(expectedRemovedLines, actualRemovedLines) {
-		t.Errorf("Expected removed lines %v, got %v", expectedRemovedLines, actualRemovedLines)
+	expected := []jsonComment{
+		{Line: 1, EndLine: 1, Column: 1, EndColumn: 10, Kind: remover.CommentStandalone, Text: "// header"},
+		{Line: 2, EndLine: 2, Column: 14, EndColumn: 23, Kind: remover.CommentInline, Text: "// inline"},
+		{Line: 3, EndLine: 4, Column: 1, EndColumn: 11, Kind: remover.CommentBlock, Text: "/* block\n   more */"},
 	}
-
-	modifiedContent := strings.Join(result.ModifiedLines, "\n")
-	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
-		t.Error("Multi-line comment should be preserved")
+	if !reflect.DeepEqual(file.Comments, expected) {
+		t.Errorf("Expected comments %+v, got %+v", expected, file.Comments)
 	}
-	if !strings.Contains(modifiedContent, "// This should NOT be removed") {
-		t.Error("Comment inside multi-line comment should be preserved")
+
+	if len(report.Skipped) != 1 || report.Skipped[0].Reason != "symlink" {
+		t.Errorf("Expected skipped file in report, got %+v", report.Skipped)
 	}
-	if !strings.Contains(modifiedContent, `"String with // comment inside"`) {
-		t.Error("Comment inside string should be preserved")
+	if report.Stats.FilesProcessed != 1 || report.Stats.TotalComments != 3 {
+		t.Errorf("Unexpected stats: %+v", report.Stats)
 	}
 }
 
-func TestDiscoverFiles(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "test_discover_*")
+func TestSARIFReport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_sarif_*")
 	if err != nil {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Create test files
-	testFiles := map[string]string{
-		"test.go":  "package main\n// comment\nfunc main() {}",
-		"test.js":  "// comment\nconsole.log('hello');",
-		"test.sql": "-- comment\nSELECT * FROM users;",
-		"test.txt": "This should be ignored",
+	path := filepath.Join(tempDir, "a.ts")
+	content := "const a = 1;\r\n// header\r\nconst é = 1; // inline\r\n// const b = compute(a);\r\n/* block\r\n   more */ const c = 3;\r\n// tail"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	for filename, content := range testFiles {
-		filePath := tempDir + "/" + filename
-		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-			t.Fatalf("Failed to create test file %s: %v", filename, err)
-		}
-	}
+	options := ProcessingOptions{Format: FormatSARIF, Check: true, RemoveBlocks: true}
+	stats := ProcessMultipleFiles([]remover.FileInfo{{Path: path, Language: remover.SupportedLanguages()["typescript"]}}, options, 0)
 
-	files, err := DiscoverFiles(tempDir, false, []string{})
-	if err != nil {
-		t.Fatalf("DiscoverFiles failed: %v", err)
+	var buf bytes.Buffer
+	if err := writeReport(&buf, options, stats); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
 	}
 
-	expectedCount := 3 // .go, .js, .sql files
-	if len(files) != expectedCount {
-		t.Errorf("Expected %d files, got %d", expectedCount, len(files))
-	}
+	validateSARIF(t, buf.Bytes())
 
-	// Check that we got the right file types
-	extensions := make(map[string]bool)
-	for _, file := range files {
-		for _, ext := range file.Language.Extensions {
-			extensions[ext] = true
-		}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	var ruleIDs []string
+	for _, result := range log.Runs[0].Results {
+		ruleIDs = append(ruleIDs, result.RuleID)
+	}
+	expectedRules := []string{"standalone-comment", "inline-comment", "commented-out-code", "block-comment", "standalone-comment"}
+	if !reflect.DeepEqual(ruleIDs, expectedRules) {
+		t.Errorf("Expected rules %v, got %v", expectedRules, ruleIDs)
+	}
+	if region := log.Runs[0].Results[1].Locations[0].PhysicalLocation.Region; region.StartColumn != 14 || region.EndColumn != 23 {
+		t.Errorf("Expected UTF-16 columns 14-23 for the inline comment, got %d-%d", region.StartColumn, region.EndColumn)
 	}
 
-	expectedExtensions := []string{".go", ".js", ".sql"}
-	for _, ext := range expectedExtensions {
-		if !extensions[ext] {
-			t.Errorf("Expected to find files with extension %s", ext)
+	var replacements []sarifReplacement
+	for i, result := range log.Runs[0].Results {
+		region := result.Locations[0].PhysicalLocation.Region
+		if region.StartLine < 1 || region.EndLine < region.StartLine || region.StartColumn < 1 {
+			t.Errorf("Result %d: invalid region %+v", i, region)
 		}
+		if len(result.Fixes) != 1 || len(result.Fixes[0].ArtifactChanges) != 1 {
+			t.Fatalf("Result %d: expected one fix with one artifact change in check mode, got %+v", i, result.Fixes)
+		}
+		replacements = append(replacements, result.Fixes[0].ArtifactChanges[0].Replacements...)
+	}
+	fixed := []byte(content)
+	for i := len(replacements) - 1; i >= 0; i-- {
+		replacement := replacements[i]
+		start := *replacement.DeletedRegion.ByteOffset
+		end := start + *replacement.DeletedRegion.ByteLength
+		fixed = append(fixed[:start:start], append([]byte(replacement.InsertedContent.Text), fixed[end:]...)...)
+	}
+	expected := "const a = 1;\r\nconst é = 1;\r\nconst c = 3;"
+	if string(fixed) != expected {
+		t.Errorf("Applying fixes: expected %q, got %q", expected, string(fixed))
 	}
 }
 
-func TestRemoveSingleLineMultilineComment(t *testing.T) {
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+// validateSARIF checks a report against the official SARIF 2.1.0 schema,
+// vendored from OASIS in testdata.
+func validateSARIF(t *testing.T, report []byte) {
+	t.Helper()
+	schemaFile, err := os.Open(filepath.Join("testdata", "sarif-schema-2.1.0.json"))
+	if err != nil {
+		t.Fatalf("Failed to open SARIF schema: %v", err)
 	}
-
-	// Single-line multi-line comment
-	line := "   /* This is a single-line multi-line comment */   "
-	removed, content := RemoveSingleLineMultilineComment(line, lang)
-	if !removed {
-		t.Errorf("Expected single-line multi-line comment to be removed")
+	defer schemaFile.Close()
+	schemaDoc, err := jsonschema.UnmarshalJSON(schemaFile)
+	if err != nil {
+		t.Fatalf("Failed to parse SARIF schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(sarifSchema, schemaDoc); err != nil {
+		t.Fatalf("Failed to add SARIF schema: %v", err)
 	}
-	if content != line {
-		t.Errorf("Expected content to match original line")
+	schema, err := compiler.Compile(sarifSchema)
+	if err != nil {
+		t.Fatalf("Failed to compile SARIF schema: %v", err)
 	}
 
-	// Not a single-line multi-line comment (just the markers)
-	line2 := "/* */"
-	removed2, _ := RemoveSingleLineMultilineComment(line2, lang)
-	if removed2 {
-		t.Errorf("Did not expect just the markers to be removed as single-line comment")
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(report))
+	if err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("Report does not match the SARIF 2.1.0 schema: %v", err)
 	}
 }
 
-func TestProcessFile_RemoveSingleLineMultiline(t *testing.T) {
-	content := `package main
+func TestOutputFormatUsage(t *testing.T) {
+	defer func(w io.Writer) { reportOutput = w }(reportOutput)
+	var help bytes.Buffer
+	reportOutput = &help
+	showHelpMessage(false)
 
-func main() {
-	fmt.Println("Hello")
-	/* This is a multi-line comment
-	   // This should NOT be removed
-	   End of multi-line comment */
-	fmt.Println("String with // comment inside")
-	/* Single-line multi-line comment */
-}`
+	err := checkOutputFormat("xml")
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported format")
+	}
+	for _, format := range outputFormats {
+		if !strings.Contains(help.String(), format) {
+			t.Errorf("Expected --help to list format %q", format)
+		}
+		if !strings.Contains(err.Error(), format) {
+			t.Errorf("Expected the invalid-format error to list %q, got %q", format, err)
+		}
+		if err := checkOutputFormat(format); err != nil {
+			t.Errorf("Expected format %q to be accepted, got %v", format, err)
+		}
+	}
+}
 
-	tmpFile, err := os.CreateTemp("", "test_*.go")
+func TestXMLReports(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_xml_*")
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
-	defer os.Remove(tmpFile.Name())
+	defer os.RemoveAll(tempDir)
 
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
+	path := filepath.Join(tempDir, "a.go")
+	if err := os.WriteFile(path, []byte("package a\n\n// gone\nvar x = 1 // inline\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
-	tmpFile.Close()
-
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+	clean := filepath.Join(tempDir, "b.go")
+	if err := os.WriteFile(clean, []byte("package a\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
+	missing := filepath.Join(tempDir, "missing.go")
 
-	result, err := ProcessFile(tmpFile.Name(), lang, false, true, []string{})
-	if err != nil {
-		t.Fatalf("ProcessFile failed: %v", err)
+	files := []remover.FileInfo{
+		{Path: path, Language: remover.SupportedLanguages()["go"]},
+		{Path: clean, Language: remover.SupportedLanguages()["go"]},
+		{Path: missing, Language: remover.SupportedLanguages()["go"]},
 	}
 
-	if result.CommentsRemoved != 1 {
-		t.Errorf("Expected 1 comment removed, got %d", result.CommentsRemoved)
-	}
+	t.Run("checkstyle", func(t *testing.T) {
+		options := ProcessingOptions{Format: FormatCheckstyle}
+		var buf bytes.Buffer
+		if err := writeReport(&buf, options, ProcessMultipleFiles(files, options, 0)); err != nil {
+			t.Fatalf("writeReport failed: %v", err)
+		}
 
-	for _, comment := range result.RemovedComments {
-		if !strings.Contains(comment.Content, "Single-line multi-line comment") {
-			t.Errorf("Expected removed comment to be the single-line multi-line comment, got: %s", comment.Content)
+		var report checkstyleReport
+		if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("Report is not valid XML: %v\n%s", err, buf.String())
 		}
-	}
+		if len(report.Files) != 3 {
+			t.Fatalf("Expected one file element per file, got %d", len(report.Files))
+		}
+		expected := []checkstyleError{
+			{Line: 3, Column: 1, Severity: "info", Message: "Comment on a line of its own: // gone", Source: "commenter.standalone-comment"},
+			{Line: 4, Column: 11, Severity: "info", Message: "Comment at the end of a code line: // inline", Source: "commenter.inline-comment"},
+		}
+		if !reflect.DeepEqual(report.Files[0].Errors, expected) {
+			t.Errorf("Expected errors %+v, got %+v", expected, report.Files[0].Errors)
+		}
+		if len(report.Files[1].Errors) != 0 {
+			t.Errorf("Expected no errors for clean file, got %+v", report.Files[1].Errors)
+		}
+		if errs := report.Files[2].Errors; len(errs) != 1 || errs[0].Severity != "error" {
+			t.Errorf("Expected processing error for missing file, got %+v", errs)
+		}
+	})
 
-	modifiedContent := strings.Join(result.ModifiedLines, "\n")
-	if strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
-		t.Error("Single-line multi-line comment should be removed")
-	}
+	t.Run("junit", func(t *testing.T) {
+		options := ProcessingOptions{Format: FormatJUnit}
+		var buf bytes.Buffer
+		if err := writeReport(&buf, options, ProcessMultipleFiles(files, options, 0)); err != nil {
+			t.Fatalf("writeReport failed: %v", err)
+		}
+
+		var report junitReport
+		if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("Report is not valid XML: %v\n%s", err, buf.String())
+		}
+		if report.Tests != 4 || report.Failures != 3 || len(report.Suites) != 3 {
+			t.Errorf("Expected 4 tests, 3 failures in 3 suites, got %d, %d in %d", report.Tests, report.Failures, len(report.Suites))
+		}
+		if failure := report.Suites[2].Cases[0].Failure; failure == nil || failure.Type != "processing-error" {
+			t.Errorf("Expected processing error to be a failure, got %+v", failure)
+		}
+		if report.Suites[1].Cases[0].Failure != nil {
+			t.Errorf("Expected clean file to pass")
+		}
+	})
 }
 
-func TestPHPCommentRemoval(t *testing.T) {
-	content := `<?php
-// This is a single-line comment
-/* This is a multi-line comment
-   that spans multiple lines */
-
-class Example {
-    // Class property comment
-    private $name = "test";
-    
-    /* Single-line multi-line comment */
-    
-    public function __construct() {
-        // Constructor comment
-        echo "Hello World"; // Inline comment
-    }
-}`
-
-	tmpFile, err := os.CreateTemp("", "test_*.php")
+func TestProcessMultipleFilesConcurrent(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_jobs_*")
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
-	defer os.Remove(tmpFile.Name())
+	defer os.RemoveAll(tempDir)
 
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
+	var files []remover.FileInfo
+	for i := 0; i < 40; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("file%02d.ts", i))
+		content := strings.Repeat("// comment\nconst x = 1; // inline\n", i%5+1)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		files = append(files, remover.FileInfo{Path: path, Language: remover.SupportedLanguages()["typescript"]})
 	}
-	tmpFile.Close()
+	files = append(files, remover.FileInfo{Path: filepath.Join(tempDir, "missing.ts"), Language: remover.SupportedLanguages()["typescript"]})
+
+	sequential := ProcessMultipleFiles(files, ProcessingOptions{Format: FormatJSON, Jobs: 1}, 0)
+	concurrent := ProcessMultipleFiles(files, ProcessingOptions{Format: FormatJSON, Jobs: 8, Write: true}, 0)
 
-	lang := Language{
-		Name:            "PHP",
-		Extensions:      []string{".php", ".phtml"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+	if concurrent.TotalComments != sequential.TotalComments || concurrent.FilesProcessed != 40 || concurrent.SuccessfulWrites != 40 {
+		t.Errorf("Unexpected stats: %+v", concurrent)
+	}
+	if !reflect.DeepEqual(concurrent.Errors, sequential.Errors) || len(concurrent.Errors) != 1 {
+		t.Errorf("Expected the same single error, got %v and %v", concurrent.Errors, sequential.Errors)
+	}
+	for i, fr := range concurrent.Results {
+		if fr.File.Path != files[i].Path {
+			t.Fatalf("Result %d out of order: expected %s, got %s", i, files[i].Path, fr.File.Path)
+		}
 	}
 
-	result, err := ProcessFile(tmpFile.Name(), lang, false, false, []string{})
+	data, err := os.ReadFile(files[3].Path)
 	if err != nil {
-		t.Fatalf("ProcessFile failed: %v", err)
+		t.Fatalf("Failed to read written file: %v", err)
 	}
-
-	if result.CommentsRemoved != 4 {
-		t.Errorf("Expected 4 comments removed, got %d", result.CommentsRemoved)
+	if expected := strings.Repeat("const x = 1;\n", 4); string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(data))
 	}
+}
 
-	modifiedContent := strings.Join(result.ModifiedLines, "\n")
-	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
-		t.Error("Multi-line comment should be preserved")
+func TestFilterMode(t *testing.T) {
+	defer func(w io.Writer) { reportOutput = w }(reportOutput)
+
+	tests := []struct {
+		name     string
+		input    string
+		filename string
+		langKey  string
+		options  ProcessingOptions
+		stdout   string
+		report   string
+		exitCode int
+	}{
+		{
+			name:     "language from filename",
+			input:    "const a = 1; // x\r\n// y\r\nlet b;",
+			filename: "src/a.ts",
+			options:  ProcessingOptions{NoColor: true},
+			stdout:   "const a = 1;\r\nlet b;",
+			report:   "Comments removed: 2",
+		},
+		{
+			name:     "language flag wins",
+			input:    "x = 1 -- c\n",
+			filename: "query.txt",
+			langKey:  "sql",
+			options:  ProcessingOptions{Format: FormatJSON},
+			stdout:   "x = 1\n",
+			report:   `"path": "query.txt"`,
+		},
+		{
+			name:     "check mode writes nothing",
+			input:    "a(); // b\n",
+			langKey:  "typescript",
+			options:  ProcessingOptions{Check: true},
+			report:   "<stdin>:1: // b",
+			exitCode: 1,
+		},
+		{
+			name:     "minified input passes through",
+			input:    "a();" + strings.Repeat(" ", 5000) + "// b",
+			filename: "a.min.js",
+			options:  ProcessingOptions{NoColor: true},
+			stdout:   "a();" + strings.Repeat(" ", 5000) + "// b",
+			report:   "Skipped a.min.js",
+		},
+		{
+			name:     "missing language",
+			input:    "a\n",
+			options:  ProcessingOptions{NoColor: true},
+			exitCode: 1,
+		},
 	}
-	if !strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
-		t.Error("Single-line multi-line comment should be preserved when flag is false")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, report bytes.Buffer
+			reportOutput = &report
+			code := runFilter(strings.NewReader(tt.input), &stdout, tt.filename, tt.langKey, tt.options, time.Now())
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("Expected stdout %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(report.String(), tt.report) {
+				t.Errorf("Expected report to contain %q, got %q", tt.report, report.String())
+			}
+		})
 	}
 }
 
-func TestCSharpCommentRemoval(t *testing.T) {
-	content := `using System;
-
-// This is a single-line comment
-/* This is a multi-line comment
-   that spans multiple lines */
-
-namespace Example
-{
-    // Class comment
-    public class Program
-    {
-        // Property comment
-        public string Name { get; set; }
-        
-        /* Single-line multi-line comment */
-        
-        public void Test()
-        {
-            string message = "String with // comment inside";
-            Console.WriteLine(message);
-        }
-    }
-}`
-
-	tmpFile, err := os.CreateTemp("", "test_*.cs")
+func TestWriteFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_write_*")
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
-	defer os.Remove(tmpFile.Name())
+	defer os.RemoveAll(tempDir)
 
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
+	target := filepath.Join(tempDir, "script.ts")
+	if err := os.WriteFile(target, []byte("old"), 0755); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(target, 0755); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
 	}
-	tmpFile.Close()
 
-	lang := Language{
-		Name:            "C#",
-		Extensions:      []string{".cs"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+	if err := WriteFile(target, []byte("new"), false); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
 	}
 
-	result, err := ProcessFile(tmpFile.Name(), lang, false, true, []string{})
+	info, err := os.Stat(target)
 	if err != nil {
-		t.Fatalf("ProcessFile failed: %v", err)
+		t.Fatalf("Failed to stat written file: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 0755 to be preserved, got %v", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("Expected content %q, got %q", "new", string(data))
 	}
 
-	if result.CommentsRemoved != 4 {
-		t.Errorf("Expected 4 comments removed, got %d", result.CommentsRemoved)
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read temp dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
 	}
 
-	modifiedContent := strings.Join(result.ModifiedLines, "\n")
-	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
-		t.Error("Multi-line comment should be preserved")
+	link := filepath.Join(tempDir, "link.ts")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
 	}
-	if strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
-		t.Error("Single-line multi-line comment should be removed when flag is true")
+
+	if err := WriteFile(link, []byte("via link"), false); !errors.Is(err, ErrSymlink) {
+		t.Errorf("Expected ErrSymlink, got %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("Expected target to be untouched, got %q", string(data))
 	}
-}
 
-func TestIgnorePatterns(t *testing.T) {
-	content := `// This is a regular comment
-// @ts-ignore This should be preserved
-// @deprecated This should be preserved
-// TODO: This should be preserved
-// FIXME: This should be preserved
-
-function test() {
-    // Regular inline comment
-    const x = 42; // @ts-ignore inline ignore comment
-    const y = 10; // Regular inline comment
-    
-    /* Regular multi-line comment */
-    
-    /* @ts-ignore multi-line ignore comment */
-    
-    return x + y;
+	if err := WriteFile(link, []byte("via link"), true); err != nil {
+		t.Fatalf("WriteFile with followSymlinks failed: %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "via link" {
+		t.Errorf("Expected target to be written through link, got %q", string(data))
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected link to remain a symlink")
+	}
 }
 
-// @ts-expect-error This should be preserved too`
-
-	tmpFile, err := os.CreateTemp("", "test_*.ts")
+func TestProcessMultipleFiles_WriteOnlyChanged(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_write_changed_*")
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to create temp dir: %v", err)
 	}
-	defer os.Remove(tmpFile.Name())
+	defer os.RemoveAll(tempDir)
 
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
+	clean := filepath.Join(tempDir, "clean.ts")
+	dirty := filepath.Join(tempDir, "dirty.ts")
+	if err := os.WriteFile(clean, []byte("const a = 1;\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
-	tmpFile.Close()
-
-	lang := Language{
-		Name:            "TypeScript/JavaScript",
-		Extensions:      []string{".ts", ".tsx", ".js", ".jsx"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
+	if err := os.WriteFile(dirty, []byte("const b = 2; // note\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
-
-	ignorePatterns := []string{"@ts-ignore", "@deprecated", "TODO", "FIXME", "@ts-expect-error"}
-
-	result, err := ProcessFile(tmpFile.Name(), lang, false, true, ignorePatterns)
-	if err != nil {
-		t.Fatalf("ProcessFile failed: %v", err)
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(clean, old, old); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
 	}
 
-	// Should only remove 3 comments: regular standalone comment, regular inline comment, and regular multi-line comment
-	if result.CommentsRemoved != 3 {
-		t.Errorf("Expected 3 comments removed, got %d", result.CommentsRemoved)
+	lang := remover.SupportedLanguages()["typescript"]
+	files := []remover.FileInfo{{Path: clean, Language: lang}, {Path: dirty, Language: lang}}
+	link := filepath.Join(tempDir, "link.ts")
+	if err := os.Symlink(dirty, link); err == nil {
+		files = append(files, remover.FileInfo{Path: link, Language: lang})
 	}
 
-	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	stats := ProcessMultipleFiles(files, ProcessingOptions{Write: true, Format: FormatJSON}, 0)
 
-	// Check that ignore pattern comments are preserved
-	preservedComments := []string{
-		"@ts-ignore This should be preserved",
-		"@deprecated This should be preserved",
-		"TODO: This should be preserved",
-		"FIXME: This should be preserved",
-		"@ts-ignore inline ignore comment",
-		"@ts-ignore multi-line ignore comment",
-		"@ts-expect-error This should be preserved too",
+	info, err := os.Stat(clean)
+	if err != nil {
+		t.Fatalf("Failed to stat unchanged file: %v", err)
 	}
-
-	for _, comment := range preservedComments {
-		if !strings.Contains(modifiedContent, comment) {
-			t.Errorf("Expected preserved comment containing: %s", comment)
-		}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("Expected unchanged file to keep mtime %v, got %v", old, info.ModTime())
 	}
-
-	// Check that regular comments are removed
-	removedComments := []string{
-		"Regular inline comment",
+	if data, _ := os.ReadFile(dirty); string(data) != "const b = 2;\n" {
+		t.Errorf("Expected changed file to be written, got %q", string(data))
+	}
+	if stats.SuccessfulWrites != 1 {
+		t.Errorf("Expected 1 write, got %d", stats.SuccessfulWrites)
 	}
 
-	for _, comment := range removedComments {
-		if strings.Contains(modifiedContent, comment) {
-			t.Errorf("Expected removed comment: %s", comment)
+	if len(files) == 3 {
+		if stats.FilesProcessed != 2 || stats.FilesSkipped != 1 || len(stats.Results) != 2 {
+			t.Errorf("Expected the symlink to be skipped, not processed: %d processed, %d skipped, %d results", stats.FilesProcessed, stats.FilesSkipped, len(stats.Results))
+		}
+		if len(stats.Skipped) != 1 || stats.Skipped[0].Path != link {
+			t.Errorf("Expected the symlink in the skipped files, got %+v", stats.Skipped)
 		}
 	}
 }
 
-func TestShouldIgnoreComment(t *testing.T) {
-	ignorePatterns := []string{"@ts-ignore", "TODO", "FIXME"}
-
-	tests := []struct {
-		name     string
-		comment  string
-		expected bool
-	}{
-		{
-			name:     "standalone ignore comment",
-			comment:  "// @ts-ignore This should be ignored",
-			expected: true,
-		},
-		{
-			name:     "inline ignore comment",
-			comment:  "const x = 42; // @ts-ignore inline comment",
-			expected: true,
-		},
-		{
-			name:     "multi-line ignore comment",
-			comment:  "/* @ts-ignore multi-line comment */",
-			expected: true,
-		},
-		{
-			name:     "TODO comment",
-			comment:  "// TODO: Fix this later",
-			expected: true,
-		},
-		{
-			name:     "FIXME comment",
-			comment:  "// FIXME: This needs attention",
-			expected: true,
-		},
-		{
-			name:     "regular comment",
-			comment:  "// This is a regular comment",
-			expected: false,
-		},
-		{
-			name:     "SQL comment with ignore pattern",
-			comment:  "-- @ts-ignore SQL comment",
-			expected: true,
-		},
-		{
-			name:     "SQL comment without ignore pattern",
-			comment:  "-- Regular SQL comment",
-			expected: false,
-		},
+func TestIgnorePatternFlag(t *testing.T) {
+	var patterns patternList
+	fs := flag.NewFlagSet("commenter", flag.ContinueOnError)
+	fs.Var(&patterns, "ignore-pattern", "")
+	fs.Var(&patterns, "i", "")
+	if err := fs.Parse([]string{"-i", "re:^x{1,3}$", "--ignore-pattern", "go:generate", "-i", "TODO,FIXME"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := shouldIgnoreComment(tt.comment, ignorePatterns)
-			if result != tt.expected {
-				t.Errorf("Expected %v, got %v for comment: %s", tt.expected, result, tt.comment)
-			}
-		})
+	expected := patternList{"re:^x{1,3}$", "go:generate", "TODO,FIXME"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Expected %q, got %q", expected, patterns)
+	}
+	if _, err := remover.CompileIgnorePatterns(patterns); err != nil {
+		t.Errorf("Expected the patterns to compile, got %v", err)
 	}
 }
 
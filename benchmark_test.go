@@ -3,145 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
-)
-
-func BenchmarkRemoveSingleLineComment(b *testing.B) {
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
-	}
-
-	testLines := []string{
-		"// This is a standalone comment",
-		`fmt.Println("Hello World") // This is an inline comment`,
-		`fmt.Println("No comment here")`,
-		`fmt.Println("String with // comment inside")`,
-		"var x = 42 // Another inline comment",
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, line := range testLines {
-			RemoveSingleLineComment(line, lang, false, false, false)
-		}
-	}
-}
-
-func BenchmarkUpdateMultiLineCommentState(b *testing.B) {
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
-	}
-
-	testLines := []string{
-		"/* Start of comment",
-		"Inside comment",
-		"Still inside",
-		"End of comment */",
-		"Regular code line",
-		"/* Complete comment */",
-	}
-
-	b.ResetTimer()
-	state := false
-	for i := 0; i < b.N; i++ {
-		for _, line := range testLines {
-			state = UpdateMultiLineCommentState(line, lang, state)
-		}
-	}
-}
-
-func BenchmarkIsInsideStringLiteral(b *testing.B) {
-	testLine := `fmt.Println("This is a string with // comment inside") // Real comment`
-	positions := []int{10, 25, 35, 45, 55}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, pos := range positions {
-			IsInsideStringLiteral(testLine, pos)
-		}
-	}
-}
-
-func BenchmarkProcessFile(b *testing.B) {
-	content := `package main
-
-import "fmt"
-
-func main() {
-	fmt.Println("Hello World")
-	
-	/* This is a multi-line comment
-	   // This should NOT be removed
-	   End of multi-line comment */
-	   
-	fmt.Println("String with // comment inside")
-	
-	var x = 42
-	
-	// Multiple
-	// Sequential
-	// Comments
-	
-	fmt.Printf("Value: %d\n", x)
-}
 
-`
-
-	tmpFile, err := os.CreateTemp("", "benchmark_*.go")
-	if err != nil {
-		b.Fatalf("Failed to create temp file: %v", err)
-	}
-	defer os.Remove(tmpFile.Name())
-
-	if _, err := tmpFile.WriteString(content); err != nil {
-		b.Fatalf("Failed to write to temp file: %v", err)
-	}
-	tmpFile.Close()
-
-	lang := Language{
-		Name:            "Go",
-		Extensions:      []string{".go"},
-		SingleLineStart: "//",
-		MultiLineStart:  "/*",
-		MultiLineEnd:    "*/",
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, err := ProcessFile(tmpFile.Name(), lang, false, false, []string{})
-		if err != nil {
-			b.Fatalf("ProcessFile failed: %v", err)
-		}
-	}
-}
-
-func BenchmarkGetLanguageByExtension(b *testing.B) {
-	filenames := []string{
-		"main.go",
-		"script.js",
-		"component.tsx",
-		"query.sql",
-		"config.json",
-		"README.md",
-		"style.css",
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, filename := range filenames {
-			GetLanguageByExtension(filename)
-		}
-	}
-}
+	"github.com/ur-wesley/commentRemover/remover"
+)
 
 func BenchmarkLargeFileProcessing(b *testing.B) {
 	var lines []string
@@ -174,7 +41,7 @@ func BenchmarkLargeFileProcessing(b *testing.B) {
 	}
 	tmpFile.Close()
 
-	lang := Language{
+	lang := remover.Language{
 		Name:            "Go",
 		Extensions:      []string{".go"},
 		SingleLineStart: "//",
@@ -182,13 +49,37 @@ func BenchmarkLargeFileProcessing(b *testing.B) {
 		MultiLineEnd:    "*/",
 	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, err := ProcessFile(tmpFile.Name(), lang, false, false, []string{})
-		if err != nil {
-			b.Fatalf("ProcessFile failed: %v", err)
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := remover.ProcessFile(tmpFile.Name(), lang, remover.Options{})
+			if err != nil {
+				b.Fatalf("ProcessFile failed: %v", err)
+			}
 		}
+	})
+
+	files := make([]remover.FileInfo, 16)
+	for i := range files {
+		files[i] = remover.FileInfo{Path: tmpFile.Name(), Language: lang}
+	}
+	for _, jobs := range benchmarkJobs() {
+		b.Run(fmt.Sprintf("files=%d/jobs=%d", len(files), jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				stats := ProcessMultipleFiles(files, ProcessingOptions{Jobs: jobs, NoWarnLarge: true}, 0)
+				if len(stats.Errors) > 0 {
+					b.Fatalf("ProcessMultipleFiles failed: %v", stats.Errors)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkJobs() []int {
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		return []int{1, n}
 	}
+	return []int{1}
 }
 
 func BenchmarkDiscoverFilesRecursive(b *testing.B) {
@@ -213,11 +104,27 @@ func BenchmarkDiscoverFilesRecursive(b *testing.B) {
 		}
 	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, err := DiscoverFiles(tempDir, true, []string{})
-		if err != nil {
-			b.Fatalf("DiscoverFiles failed: %v", err)
+	b.Run("discover", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := remover.DiscoverFiles(tempDir, true, []string{})
+			if err != nil {
+				b.Fatalf("DiscoverFiles failed: %v", err)
+			}
 		}
+	})
+
+	for _, jobs := range benchmarkJobs() {
+		b.Run(fmt.Sprintf("process/jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				files, err := remover.DiscoverFiles(tempDir, true, []string{})
+				if err != nil {
+					b.Fatalf("DiscoverFiles failed: %v", err)
+				}
+				stats := ProcessMultipleFiles(files, ProcessingOptions{Jobs: jobs}, 0)
+				if len(stats.Errors) > 0 {
+					b.Fatalf("ProcessMultipleFiles failed: %v", stats.Errors)
+				}
+			}
+		})
 	}
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+var checkstyleSeverities = map[string]string{
+	"note":    "info",
+	"warning": "warning",
+	"error":   "error",
+}
+
+func writeCheckstyleReport(w io.Writer, stats *ProcessingStats) error {
+	report := checkstyleReport{Version: "4.3"}
+
+	for _, fr := range stats.Results {
+		file := checkstyleFile{Name: fr.File.Path}
+		if fr.Error != "" {
+			file.Errors = append(file.Errors, checkstyleError{Line: 1, Severity: "error", Message: fr.Error, Source: "commenter.processing-error"})
+		}
+		if fr.Result != nil {
+			for _, comment := range fr.Result.RemovedComments {
+				rule := commentRules[commentRuleIndex(comment, fr.File.Language)]
+				file.Errors = append(file.Errors, checkstyleError{
+					Line:     comment.LineNumber,
+					Column:   comment.Column,
+					Severity: checkstyleSeverities[rule.DefaultConfig.Level],
+					Message:  rule.ShortDescription.Text + ": " + commentSummary(comment),
+					Source:   "commenter." + rule.ID,
+				})
+			}
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	for _, err := range unattributedErrors(stats) {
+		report.Files = append(report.Files, checkstyleFile{Name: "commenter", Errors: []checkstyleError{
+			{Line: 1, Severity: "error", Message: err, Source: "commenter.processing-error"},
+		}})
+	}
+
+	return writeXML(w, report)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ur-wesley/commentRemover/remover"
+)
+
+const utf8BOM = "\xEF\xBB\xBF"
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte
+	a    int
+	b    int
+}
+
+// formatUnifiedDiff renders the changes of result as a unified diff. The
+// script comes straight from the result's LineMap: removed lines are
+// deletions and kept lines whose text changed are replacements, so no
+// general-purpose diff is needed and the cost is linear in the file size.
+func formatUnifiedDiff(path string, result *remover.Result, useColor bool) (string, error) {
+	a, b := resultLines(result)
+	ops, err := resultDiffOps(result, a, b)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	var out strings.Builder
+	for _, hunk := range diffHunks(ops) {
+		if out.Len() == 0 {
+			name := relativeSlashPath(path)
+			fmt.Fprintf(&out, "%s--- a/%s%s\n", colorize(useColor, ColorBold), name, colorize(useColor, ColorReset))
+			fmt.Fprintf(&out, "%s+++ b/%s%s\n", colorize(useColor, ColorBold), name, colorize(useColor, ColorReset))
+		}
+
+		first := hunk[0]
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&out, "%s@@ -%s +%s @@%s\n", colorize(useColor, ColorCyan), hunkRange(first.a, aLen), hunkRange(first.b, bLen), colorize(useColor, ColorReset))
+
+		for _, op := range hunk {
+			line, color := "", ""
+			switch op.kind {
+			case ' ':
+				line = a[op.a]
+			case '-':
+				line, color = a[op.a], ColorRed
+			case '+':
+				line, color = b[op.b], ColorGreen
+			}
+			text, ending := line, ""
+			if body, ok := strings.CutSuffix(line, "\n"); ok {
+				text = strings.TrimSuffix(body, "\r")
+				ending = line[len(text):]
+			}
+			fmt.Fprintf(&out, "%s%c%s%s", colorize(useColor && color != "", color), op.kind, text, colorize(useColor && color != "", ColorReset))
+			if ending == "" {
+				out.WriteString("\n\\ No newline at end of file\n")
+			} else {
+				out.WriteString(ending)
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+}
+
+func relativeSlashPath(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// resultLines returns the original and processed lines of result with their
+// line endings, the BOM attached to the first line of each.
+func resultLines(result *remover.Result) ([]string, []string) {
+	join := func(lines, endings []string) []string {
+		joined := make([]string, len(lines))
+		for i, line := range lines {
+			joined[i] = line + endings[i]
+		}
+		if result.BOM && len(joined) > 0 {
+			joined[0] = utf8BOM + joined[0]
+		}
+		return joined
+	}
+	return join(result.SourceLines, result.SourceEndings), join(result.ModifiedLines, result.ModifiedEndings)
+}
+
+// resultDiffOps walks the line map once. Within each run of changes the
+// deletions come before the insertions, as diff tools print them. A line map
+// that does not fit the lines is reported instead of indexed.
+func resultDiffOps(result *remover.Result, a, b []string) ([]diffOp, error) {
+	if len(result.LineMap) != len(a) {
+		return nil, fmt.Errorf("line map has %d entries for %d lines", len(result.LineMap), len(a))
+	}
+	ops := make([]diffOp, 0, len(a))
+	var added []diffOp
+	j := 0
+	flush := func() {
+		ops = append(ops, added...)
+		added = added[:0]
+	}
+	for i := range a {
+		switch target := result.LineMap[i]; {
+		case target >= len(b) || target >= 0 && target < j:
+			return nil, fmt.Errorf("line %d maps to line %d of %d", i+1, target+1, len(b))
+		case target < 0:
+			ops = append(ops, diffOp{kind: '-', a: i, b: j})
+		case a[i] == b[target]:
+			flush()
+			ops = append(ops, diffOp{kind: ' ', a: i, b: target})
+			j = target + 1
+		default:
+			ops = append(ops, diffOp{kind: '-', a: i, b: target})
+			added = append(added, diffOp{kind: '+', a: i, b: target})
+			j = target + 1
+		}
+	}
+	flush()
+	return ops, nil
+}
+
+func diffHunks(ops []diffOp) [][]diffOp {
+	var hunks [][]diffOp
+	start, end := -1, -1
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		if start >= 0 && i-end-1 > 2*diffContext {
+			hunks = append(hunks, ops[start:min(end+diffContext+1, len(ops))])
+			start = -1
+		}
+		if start < 0 {
+			start = max(i-diffContext, 0)
+		}
+		end = i
+	}
+	if start >= 0 {
+		hunks = append(hunks, ops[start:min(end+diffContext+1, len(ops))])
+	}
+	return hunks
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"iter"
 	"os"
-	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
-	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/ur-wesley/commentRemover/remover"
 )
 
 type ProcessingOptions struct {
@@ -20,6 +23,22 @@ type ProcessingOptions struct {
 	ExcludePatterns           []string
 	RemoveSingleLineMultiline bool
 	IgnorePatterns            []string
+	RemoveBlocks              bool
+	RemoveDocstrings          bool
+	KeepDocComments           bool
+	RemoveDocComments         bool
+	RemoveIfZero              bool
+	StripDirectives           bool
+	DisablePragmas            bool
+	ExtraPragmas              map[string][]string
+	ReportUnusedDirectives    bool
+	FollowSymlinks            bool
+	Diff                      bool
+	PatchFile                 string
+	Check                     bool
+	Format                    string
+	Jobs                      int
+	ProcessMinified           bool
 }
 
 type ProcessingStats struct {
@@ -30,240 +49,229 @@ type ProcessingStats struct {
 	SuccessfulWrites int
 	FailedWrites     int
 	Errors           []string
+	Skipped          []SkippedFile
+	Results          []FileResult
 }
 
-type FileInfo struct {
-	Path     string
-	Language Language
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
 }
 
-func DiscoverGlobFiles(pattern string) ([]FileInfo, error) {
-	var files []FileInfo
-
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("invalid glob pattern '%s': %v", pattern, err)
-	}
-
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("no files match pattern: %s", pattern)
-	}
-
-	for _, match := range matches {
-		if stat, err := os.Stat(match); err == nil && stat.IsDir() {
-			continue
-		}
-
-		lang, supported := GetLanguageByExtension(match)
-		if supported {
-			files = append(files, FileInfo{
-				Path:     match,
-				Language: *lang,
-			})
-		}
-	}
-
-	return files, nil
+type FileResult struct {
+	File   remover.FileInfo
+	Result *remover.Result
+	Error  string
 }
 
-func DiscoverFiles(inputPath string, recursive bool, excludePatterns []string) ([]FileInfo, error) {
-	var files []FileInfo
-
-	if strings.Contains(inputPath, "*") || strings.Contains(inputPath, "?") || strings.Contains(inputPath, "[") {
-		return DiscoverGlobFiles(inputPath)
-	}
-
-	stat, err := os.Stat(inputPath)
-	if err != nil {
-		return nil, fmt.Errorf("path does not exist: %s", inputPath)
+// removerOptions returns the options that control comment removal itself.
+func (o ProcessingOptions) removerOptions() remover.Options {
+	return remover.Options{
+		Consecutive:               o.Consecutive,
+		RemoveSingleLineMultiline: o.RemoveSingleLineMultiline,
+		IgnorePatterns:            o.IgnorePatterns,
+		RemoveBlocks:              o.RemoveBlocks,
+		RemoveDocstrings:          o.RemoveDocstrings,
+		KeepDocComments:           o.KeepDocComments,
+		RemoveDocComments:         o.RemoveDocComments,
+		RemoveIfZero:              o.RemoveIfZero,
+		StripDirectives:           o.StripDirectives,
+		DisablePragmas:            o.DisablePragmas,
+		ExtraPragmas:              o.ExtraPragmas,
+		SkipMinified:              !o.ProcessMinified,
 	}
+}
 
-	var ignorePatterns []string
-	var dirToCheck string
-	if stat.IsDir() {
-		dirToCheck = inputPath
-	} else {
-		dirToCheck = filepath.Dir(inputPath)
-	}
+func (o ProcessingOptions) textOutput() bool {
+	return o.Format == "" || o.Format == FormatText
+}
 
-	gitignorePath := filepath.Join(dirToCheck, ".gitignore")
-	if data, err := os.ReadFile(gitignorePath); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
-				ignorePatterns = append(ignorePatterns, trimmed)
+func ProcessMultipleFiles(files []remover.FileInfo, options ProcessingOptions, totalDuration time.Duration) *ProcessingStats {
+	stats := &ProcessingStats{}
+	useColor := !options.NoColor
+	textOutput := options.textOutput()
+	var patch strings.Builder
+
+	for i, outcome := range processConcurrently(files, options) {
+		file := files[i]
+		result, err := outcome.result, outcome.err
+		if errors.Is(err, remover.ErrMinified) || errors.Is(err, ErrSymlink) {
+			stats.FilesSkipped++
+			stats.Skipped = append(stats.Skipped, SkippedFile{Path: file.Path, Reason: err.Error()})
+			if textOutput && errors.Is(err, ErrSymlink) {
+				printWarning(useColor, "Skipped %s: %v", file.Path, err)
+			} else if textOutput {
+				printWarning(useColor, "Skipped %s: %v (use --process-minified)", file.Path, err)
 			}
+			continue
 		}
-	}
-
-	commenterignorePath := filepath.Join(dirToCheck, ".commenterignore")
-	if data, err := os.ReadFile(commenterignorePath); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
-				ignorePatterns = append(ignorePatterns, trimmed)
+		if err != nil {
+			message := fmt.Sprintf("%s: %v", file.Path, err)
+			stats.FailedWrites++
+			stats.Errors = append(stats.Errors, message)
+			if !textOutput {
+				stats.Results = append(stats.Results, FileResult{File: file, Error: message})
 			}
+			continue
 		}
-	}
-
-	var ign *ignore.GitIgnore
-	if len(ignorePatterns) > 0 {
-		ign = ignore.CompileIgnoreLines(ignorePatterns...)
-	}
 
-	if stat.IsDir() {
-		err = processDirectory(inputPath, recursive, &files, ign, excludePatterns)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		lang, supported := GetLanguageByExtension(inputPath)
-		if !supported {
-			return nil, fmt.Errorf("unsupported file type: %s", filepath.Ext(inputPath))
+		if textOutput && !options.NoWarnLarge && !options.Check && result.OriginalLines > 500 && len(files) > 1 {
+			printWarning(useColor, "Large file: %s (%d lines)", file.Path, result.OriginalLines)
 		}
-		if ign == nil || !ign.MatchesPath(filepath.Base(inputPath)) {
-			if !matchesExcludePatterns(inputPath, excludePatterns) {
-				files = append(files, FileInfo{
-					Path:     inputPath,
-					Language: *lang,
-				})
+
+		if textOutput && options.ReportUnusedDirectives && len(files) > 1 {
+			for _, region := range result.UnusedDirectives() {
+				printWarning(useColor, "Unused %s%s directive: %s:%d", remover.ControlPrefix, region.Directive, file.Path, region.DirectiveLine)
 			}
 		}
-	}
 
-	return files, nil
-}
+		stats.FilesProcessed++
+		stats.TotalComments += result.CommentsRemoved
+		stats.TotalLines += result.OriginalLines
+		fileResult := FileResult{File: file, Result: result}
 
-func matchesExcludePatterns(filePath string, patterns []string) bool {
-	if len(patterns) == 0 {
-		return false
-	}
+		if err := outcome.writeErr; err != nil {
+			fileResult.Error = fmt.Sprintf("Failed to write %s: %v", file.Path, err)
+			stats.FailedWrites++
+			stats.Errors = append(stats.Errors, fileResult.Error)
+		} else if outcome.written {
+			stats.SuccessfulWrites++
+		}
 
-	fileName := filepath.Base(filePath)
-	for _, pattern := range patterns {
-		if matched, err := filepath.Match(pattern, fileName); err == nil && matched {
-			return true
+		if !textOutput {
+			stats.Results = append(stats.Results, fileResult)
 		}
-	}
-	return false
-}
 
-func processDirectory(dirPath string, recursive bool, files *[]FileInfo, ign *ignore.GitIgnore, excludePatterns []string) error {
-	if recursive {
-		return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			relPath, _ := filepath.Rel(dirPath, path)
-			if ign != nil && ign.MatchesPath(relPath) {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-			if info.IsDir() {
-				return nil
-			}
-			if matchesExcludePatterns(path, excludePatterns) {
-				return nil
-			}
-			lang, supported := GetLanguageByExtension(path)
-			if supported {
-				*files = append(*files, FileInfo{
-					Path:     path,
-					Language: *lang,
-				})
+		if options.PatchFile != "" {
+			if diff, err := formatUnifiedDiff(file.Path, result, false); err != nil {
+				stats.Errors = append(stats.Errors, err.Error())
+			} else {
+				patch.WriteString(diff)
 			}
-			return nil
-		})
-	} else {
-		entries, err := os.ReadDir(dirPath)
-		if err != nil {
-			return err
 		}
-		for _, entry := range entries {
-			fullPath := filepath.Join(dirPath, entry.Name())
-			relPath, _ := filepath.Rel(dirPath, fullPath)
-			if ign != nil && ign.MatchesPath(relPath) {
-				continue
-			}
-			if entry.IsDir() {
-				continue
-			}
-			if matchesExcludePatterns(fullPath, excludePatterns) {
-				continue
+
+		switch {
+		case !textOutput:
+		case options.Check:
+			for _, finding := range checkFindings(file.Path, result.RemovedComments) {
+				fmt.Fprintln(reportOutput, finding)
 			}
-			lang, supported := GetLanguageByExtension(fullPath)
-			if supported {
-				*files = append(*files, FileInfo{
-					Path:     fullPath,
-					Language: *lang,
-				})
+		case len(files) == 1:
+			printFileResult(file.Path, file.Language, result, !options.NoColor, totalDuration, !options.NoWarnLarge, options.ReportUnusedDirectives, options.Diff)
+		case options.Diff:
+			if diff, err := formatUnifiedDiff(file.Path, result, useColor && isTerminal()); err != nil {
+				stats.Errors = append(stats.Errors, err.Error())
+			} else {
+				fmt.Fprint(reportOutput, diff)
 			}
 		}
 	}
-	return nil
-}
-
-func FilterFilesByExtensions(files []FileInfo, extensions []string) []FileInfo {
-	if len(extensions) == 0 {
-		return files
-	}
 
-	var filtered []FileInfo
-	for _, file := range files {
-		fileExt := strings.ToLower(filepath.Ext(file.Path))
-		for _, ext := range extensions {
-			if strings.ToLower(ext) == fileExt {
-				filtered = append(filtered, file)
-				break
-			}
+	if options.PatchFile != "" {
+		if err := os.WriteFile(options.PatchFile, []byte(patch.String()), 0644); err != nil {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to write patch %s: %v", options.PatchFile, err))
+		} else if textOutput {
+			printSuccess(useColor, "Patch written to %s", options.PatchFile)
 		}
 	}
 
-	return filtered
+	return stats
 }
 
-func ProcessMultipleFiles(files []FileInfo, options ProcessingOptions, totalDuration time.Duration) *ProcessingStats {
-	stats := &ProcessingStats{}
-	useColor := !options.NoColor
+type fileOutcome struct {
+	result   *remover.Result
+	err      error
+	writeErr error
+	written  bool
+}
 
-	for _, file := range files {
-		result, err := ProcessFile(file.Path, file.Language, options.Consecutive, options.RemoveSingleLineMultiline, options.IgnorePatterns)
-		if err != nil {
-			stats.FailedWrites++
-			stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", file.Path, err))
-			continue
+// processConcurrently reads, processes and writes files on options.Jobs
+// workers. Outcomes are yielded in the order of files as soon as each one and
+// all before it are done, so callers can report and aggregate sequentially.
+func processConcurrently(files []remover.FileInfo, options ProcessingOptions) iter.Seq2[int, fileOutcome] {
+	return func(yield func(int, fileOutcome) bool) {
+		jobs := options.Jobs
+		if jobs <= 0 {
+			jobs = runtime.GOMAXPROCS(0)
 		}
+		jobs = min(jobs, len(files))
 
-		if !options.NoWarnLarge && result.OriginalLines > 500 && len(files) > 1 {
-			printWarning(useColor, "Large file: %s (%d lines)", file.Path, result.OriginalLines)
+		removerOptions := options.removerOptions()
+		outcomes := make([]fileOutcome, len(files))
+		done := make([]chan struct{}, len(files))
+		for i := range done {
+			done[i] = make(chan struct{})
 		}
 
-		stats.FilesProcessed++
-		stats.TotalComments += result.CommentsRemoved
-		stats.TotalLines += result.OriginalLines
-
-		if options.Write {
-			if err := WriteFile(file.Path, result.ModifiedLines); err != nil {
-				stats.FailedWrites++
-				stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to write %s: %v", file.Path, err))
-			} else {
-				stats.SuccessfulWrites++
+		next := make(chan int)
+		stop := make(chan struct{})
+		defer close(stop)
+
+		go func() {
+			defer close(next)
+			for i := range files {
+				select {
+				case next <- i:
+				case <-stop:
+					return
+				}
 			}
+		}()
+
+		for w := 0; w < jobs; w++ {
+			go func() {
+				for i := range next {
+					outcome := &outcomes[i]
+					if options.Write && !options.FollowSymlinks && isSymlink(files[i].Path) {
+						outcome.err = ErrSymlink
+					} else if result, err := remover.ProcessFile(files[i].Path, files[i].Language, removerOptions); err != nil {
+						outcome.err = err
+					} else {
+						outcome.result = &result
+					}
+					// Unchanged files are left alone, keeping their inode and mtime.
+					if outcome.err == nil && options.Write {
+						if content := outcome.result.Content(); !bytes.Equal(content, outcome.result.Original()) {
+							outcome.writeErr = WriteFile(files[i].Path, content, options.FollowSymlinks)
+							outcome.written = outcome.writeErr == nil
+						}
+					}
+					close(done[i])
+				}
+			}()
 		}
 
-		if len(files) == 1 {
-			printFileResult(file.Path, file.Language, result, !options.NoColor, totalDuration, !options.NoWarnLarge)
+		for i := range files {
+			<-done[i]
+			outcome := outcomes[i]
+			outcomes[i] = fileOutcome{}
+			if !yield(i, outcome) {
+				return
+			}
 		}
 	}
+}
 
-	return stats
+func checkFindings(filePath string, comments []remover.RemovedComment) []string {
+	findings := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		findings = append(findings, fmt.Sprintf("%s:%d: %s", filePath, comment.LineNumber, commentSummary(comment)))
+	}
+	return findings
+}
+
+func checkExitCode(stats *ProcessingStats) int {
+	switch {
+	case len(stats.Errors) > 0:
+		return 2
+	case stats.TotalComments > 0:
+		return 1
+	default:
+		return 0
+	}
 }
 
-func printFileResult(filePath string, lang Language, result *CommentRemovalResult, useColor bool, duration time.Duration, showLargeWarning bool) {
+func printFileResult(filePath string, lang remover.Language, result *remover.Result, useColor bool, duration time.Duration, showLargeWarning bool, reportUnused bool, showDiff bool) {
 	printInfo(useColor, "File: %s (%s)", filePath, lang.Name)
 
 	if showLargeWarning && result.OriginalLines > 500 {
@@ -274,41 +282,82 @@ func printFileResult(filePath string, lang Language, result *CommentRemovalResul
 	printStat(useColor, "Comments removed", result.CommentsRemoved)
 	printStat(useColor, "Remaining lines", result.RemainingLines)
 
-	if len(result.RemovedComments) > 0 {
-		fmt.Printf("\n%sRemoved comments:%s\n", colorize(useColor, ColorYellow+ColorBold), colorize(useColor, ColorReset))
+	if showDiff {
+		if diff, err := formatUnifiedDiff(filePath, result, useColor && isTerminal()); err != nil {
+			printError(useColor, "%v", err)
+		} else if diff != "" {
+			fmt.Fprintf(reportOutput, "\n%s", diff)
+		}
+	} else if len(result.RemovedComments) > 0 {
+		fmt.Fprintf(reportOutput, "\n%sRemoved comments:%s\n", colorize(useColor, ColorYellow+ColorBold), colorize(useColor, ColorReset))
 		for _, comment := range result.RemovedComments {
-			fmt.Printf("  %sLine %d:%s %s%s%s\n",
+			label := fmt.Sprintf("Line %d", comment.LineNumber)
+			content := strings.TrimSpace(comment.Content)
+			if comment.EndLineNumber > comment.LineNumber {
+				label = fmt.Sprintf("Lines %d-%d", comment.LineNumber, comment.EndLineNumber)
+				content = strings.TrimSpace(strings.SplitN(content, "\n", 2)[0]) + " ..."
+			}
+			fmt.Fprintf(reportOutput, "  %s%s:%s %s%s%s\n",
 				colorize(useColor, ColorBlue),
-				comment.LineNumber,
+				label,
 				colorize(useColor, ColorReset),
 				colorize(useColor, ColorDim),
-				strings.TrimSpace(comment.Content),
+				content,
 				colorize(useColor, ColorReset))
 		}
 	}
 
+	if len(result.ProtectedRegions) > 0 {
+		fmt.Fprintf(reportOutput, "\n%sProtected regions:%s\n", colorize(useColor, ColorYellow+ColorBold), colorize(useColor, ColorReset))
+		for _, region := range result.ProtectedRegions {
+			label := fmt.Sprintf("Line %d", region.StartLine)
+			if region.EndLine > region.StartLine {
+				label = fmt.Sprintf("Lines %d-%d", region.StartLine, region.EndLine)
+			}
+			fmt.Fprintf(reportOutput, "  %s%s:%s %s%s%s%s %d comment(s) kept\n",
+				colorize(useColor, ColorBlue),
+				label,
+				colorize(useColor, ColorReset),
+				colorize(useColor, ColorDim),
+				remover.ControlPrefix,
+				region.Directive,
+				colorize(useColor, ColorReset),
+				region.Protected)
+		}
+	}
+
+	if reportUnused {
+		for _, region := range result.UnusedDirectives() {
+			printWarning(useColor, "Unused %s%s directive on line %d protected nothing", remover.ControlPrefix, region.Directive, region.DirectiveLine)
+		}
+	}
+
 	printExecutionTime(useColor, duration)
 }
 
 func printBatchStats(stats *ProcessingStats, options ProcessingOptions) {
 	useColor := !options.NoColor
 
-	fmt.Printf("\n%sBatch Processing Summary:%s\n", colorize(useColor, ColorBold+ColorCyan), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "\n%sBatch Processing Summary:%s\n", colorize(useColor, ColorBold+ColorCyan), colorize(useColor, ColorReset))
 	printStat(useColor, "Files processed", stats.FilesProcessed)
 	printStat(useColor, "Total comments removed", stats.TotalComments)
 	printStat(useColor, "Total lines processed", stats.TotalLines)
 
+	if stats.FilesSkipped > 0 {
+		printStat(useColor, "Files skipped", stats.FilesSkipped)
+	}
+
 	if options.Write {
 		printStat(useColor, "Files written successfully", stats.SuccessfulWrites)
 		if stats.FailedWrites > 0 {
-			fmt.Printf("%sFailed writes: %d%s\n", colorize(useColor, ColorRed), stats.FailedWrites, colorize(useColor, ColorReset))
+			fmt.Fprintf(reportOutput, "%sFailed writes: %d%s\n", colorize(useColor, ColorRed), stats.FailedWrites, colorize(useColor, ColorReset))
 		}
 	}
 
 	if len(stats.Errors) > 0 {
-		fmt.Printf("\n%sErrors:%s\n", colorize(useColor, ColorRed+ColorBold), colorize(useColor, ColorReset))
+		fmt.Fprintf(reportOutput, "\n%sErrors:%s\n", colorize(useColor, ColorRed+ColorBold), colorize(useColor, ColorReset))
 		for _, err := range stats.Errors {
-			fmt.Printf("  %s%s%s\n", colorize(useColor, ColorRed), err, colorize(useColor, ColorReset))
+			fmt.Fprintf(reportOutput, "  %s%s%s\n", colorize(useColor, ColorRed), err, colorize(useColor, ColorReset))
 		}
 	}
 }
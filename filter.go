@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ur-wesley/commentRemover/remover"
+)
+
+// stdinPath is the input argument that selects filter mode.
+const stdinPath = "-"
+
+// filterLanguage picks the language for stdin from --lang, or else from the
+// extension of --stdin-filename.
+func filterLanguage(filename, langKey string) (remover.Language, error) {
+	if langKey != "" {
+		if lang, ok := remover.LanguageByKey(strings.ToLower(langKey)); ok {
+			return lang, nil
+		}
+		keys := slices.Sorted(maps.Keys(remover.SupportedLanguages()))
+		return remover.Language{}, fmt.Errorf("unsupported language: %s (expected one of %s)", langKey, strings.Join(keys, ", "))
+	}
+	if filename == "" {
+		return remover.Language{}, errors.New("reading from stdin requires --stdin-filename or --lang")
+	}
+	lang, ok := remover.GetLanguageByExtension(filename)
+	if !ok {
+		return remover.Language{}, fmt.Errorf("unsupported file type: %s", filename)
+	}
+	return *lang, nil
+}
+
+// runFilter reads source from in and writes the processed source to out. The
+// report goes to reportOutput in the selected format. Minified input is
+// passed through unchanged; in check mode nothing is written to out. It
+// returns the exit code.
+func runFilter(in io.Reader, out io.Writer, filename, langKey string, options ProcessingOptions, startTime time.Time) int {
+	useColor := !options.NoColor && isTerminal()
+	errorExitCode := 1
+	if options.Check {
+		errorExitCode = 2
+	}
+
+	lang, err := filterLanguage(filename, langKey)
+	if err != nil {
+		printError(useColor, "%v", err)
+		return errorExitCode
+	}
+	name := filename
+	if name == "" {
+		name = "<stdin>"
+	}
+	file := remover.FileInfo{Path: name, Language: lang}
+
+	src, err := io.ReadAll(in)
+	if err != nil {
+		printError(useColor, "Failed to read stdin: %v", err)
+		return errorExitCode
+	}
+
+	stats := &ProcessingStats{}
+	result, err := remover.Remove(src, lang, options.removerOptions())
+	switch {
+	case errors.Is(err, remover.ErrMinified):
+		stats.FilesSkipped++
+		stats.Skipped = append(stats.Skipped, SkippedFile{Path: name, Reason: err.Error()})
+		if !options.Check {
+			if _, err := out.Write(src); err != nil {
+				printError(useColor, "Failed to write stdout: %v", err)
+				return errorExitCode
+			}
+		}
+		if options.textOutput() {
+			printWarning(useColor, "Skipped %s: %v (use --process-minified)", name, err)
+		}
+	case err != nil:
+		message := fmt.Sprintf("%s: %v", name, err)
+		stats.FailedWrites++
+		stats.Errors = append(stats.Errors, message)
+		stats.Results = append(stats.Results, FileResult{File: file, Error: message})
+		if options.textOutput() {
+			printError(useColor, "%s", message)
+		}
+	default:
+		stats.FilesProcessed++
+		stats.TotalComments += result.CommentsRemoved
+		stats.TotalLines += result.OriginalLines
+		stats.Results = append(stats.Results, FileResult{File: file, Result: &result})
+		if !options.Check {
+			if _, err := out.Write(result.Content()); err != nil {
+				printError(useColor, "Failed to write stdout: %v", err)
+				return errorExitCode
+			}
+		}
+		switch {
+		case !options.textOutput():
+		case options.Check:
+			for _, finding := range checkFindings(name, result.RemovedComments) {
+				fmt.Fprintln(reportOutput, finding)
+			}
+		default:
+			printFileResult(name, lang, &result, useColor, time.Since(startTime), !options.NoWarnLarge, options.ReportUnusedDirectives, options.Diff)
+		}
+	}
+
+	if !options.textOutput() {
+		if err := writeReport(reportOutput, options, stats); err != nil {
+			printError(useColor, "%v", err)
+			return errorExitCode
+		}
+	}
+	if options.Check {
+		return checkExitCode(stats)
+	}
+	if len(stats.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitReport struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitReport(w io.Writer, stats *ProcessingStats) error {
+	report := junitReport{Name: "commenter"}
+
+	for _, fr := range stats.Results {
+		suite := junitSuite{Name: fr.File.Path}
+		if fr.Error != "" {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      "processing",
+				ClassName: fr.File.Path,
+				Failure:   &junitFailure{Message: fr.Error, Type: "processing-error", Text: fr.Error},
+			})
+		}
+		if fr.Result != nil {
+			for _, comment := range fr.Result.RemovedComments {
+				rule := commentRules[commentRuleIndex(comment, fr.File.Language)]
+				suite.Cases = append(suite.Cases, junitCase{
+					Name:      fmt.Sprintf("line %d: %s", comment.LineNumber, rule.ID),
+					ClassName: fr.File.Path,
+					Failure: &junitFailure{
+						Message: rule.ShortDescription.Text + ": " + commentSummary(comment),
+						Type:    rule.ID,
+						Text:    fmt.Sprintf("%s:%d:%d\n%s", fr.File.Path, comment.LineNumber, comment.Column, comment.Text),
+					},
+				})
+			}
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitCase{Name: "no removable comments", ClassName: fr.File.Path})
+		}
+		report.addSuite(suite)
+	}
+
+	if errs := unattributedErrors(stats); len(errs) > 0 {
+		suite := junitSuite{Name: "commenter"}
+		for _, err := range errs {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      "processing",
+				ClassName: "commenter",
+				Failure:   &junitFailure{Message: err, Type: "processing-error", Text: err},
+			})
+		}
+		report.addSuite(suite)
+	}
+
+	return writeXML(w, report)
+}
+
+func (r *junitReport) addSuite(suite junitSuite) {
+	suite.Tests = len(suite.Cases)
+	for _, c := range suite.Cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+	}
+	r.Tests += suite.Tests
+	r.Failures += suite.Failures
+	r.Suites = append(r.Suites, suite)
+}
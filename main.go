@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/ur-wesley/commentRemover/remover"
 )
 
 var (
@@ -56,16 +58,21 @@ func getVersionFromPackageJSON() string {
 	return version
 }
 
-
 type Config struct {
-	Write                     *bool    `json:"write"`
-	NoColor                   *bool    `json:"noColor"`
-	Recursive                 *bool    `json:"recursive"`
-	Consecutive               *bool    `json:"consecutive"`
-	NoWarnLarge               *bool    `json:"noWarnLarge"`
-	ExcludePatterns           []string `json:"excludePatterns"`
-	RemoveSingleLineMultiline *bool    `json:"removeSingleLineMultiline"`
-	IgnorePatterns            []string `json:"ignorePatterns"`
+	Write                     *bool         `json:"write"`
+	NoColor                   *bool         `json:"noColor"`
+	Recursive                 *bool         `json:"recursive"`
+	Consecutive               *bool         `json:"consecutive"`
+	NoWarnLarge               *bool         `json:"noWarnLarge"`
+	ExcludePatterns           []string      `json:"excludePatterns"`
+	RemoveSingleLineMultiline *bool         `json:"removeSingleLineMultiline"`
+	IgnorePatterns            []string      `json:"ignorePatterns"`
+	Pragmas                   *PragmaConfig `json:"pragmas"`
+}
+
+type PragmaConfig struct {
+	Disable bool                `json:"disable"`
+	Extra   map[string][]string `json:"extra"`
 }
 
 func loadConfig(configPath string) (*Config, error) {
@@ -98,16 +105,34 @@ func mergeConfigWithFlags(cfg *Config, write, noColor, recursive, consecutive, n
 		return opt
 	}
 
-
 	if len(cfg.ExcludePatterns) > 0 && len(excludeGlobs) == 0 {
 		opt.ExcludePatterns = cfg.ExcludePatterns
 	}
 	if len(cfg.IgnorePatterns) > 0 && len(ignoreGlobs) == 0 {
 		opt.IgnorePatterns = cfg.IgnorePatterns
 	}
+	if cfg.Pragmas != nil {
+		opt.DisablePragmas = cfg.Pragmas.Disable
+		opt.ExtraPragmas = cfg.Pragmas.Extra
+	}
 	return opt
 }
 
+// patternList collects the values of a flag that may be given more than once.
+type patternList []string
+
+func (p *patternList) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(*p, " ")
+}
+
+func (p *patternList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
 func main() {
 	startTime := time.Now()
 
@@ -116,11 +141,28 @@ func main() {
 	var recursive bool
 	var showHelp bool
 	var showVersion bool
+	var listPragmas bool
 	var consecutive bool
 	var noWarnLarge bool
 	var excludePatterns string
-	var ignorePatterns string
+	var ignorePatterns patternList
 	var removeSingleLineMultiline bool
+	var removeBlocks bool
+	var removeDocstrings bool
+	var keepDocComments bool
+	var removeDocComments bool
+	var removeIfZero bool
+	var stripDirectives bool
+	var reportUnusedDirectives bool
+	var followSymlinks bool
+	var showDiff bool
+	var patchFile string
+	var check bool
+	var format string
+	var jobs int
+	var processMinified bool
+	var stdinFilename string
+	var langKey string
 	var configPath string
 
 	flag.BoolVar(&write, "write", false, "Write changes to file instead of just logging")
@@ -135,17 +177,42 @@ func main() {
 	flag.BoolVar(&noWarnLarge, "nwl", false, "Disable warnings for large files (shorthand)")
 	flag.StringVar(&excludePatterns, "exclude", "", "Comma-separated glob patterns to exclude (e.g., '*test.go,*.min.js')")
 	flag.StringVar(&excludePatterns, "e", "", "Exclude patterns (shorthand)")
-	flag.StringVar(&ignorePatterns, "ignore-pattern", "", "Comma-separated patterns to ignore in comments (e.g., '@ts-ignore,@deprecated')")
-	flag.StringVar(&ignorePatterns, "i", "", "Ignore patterns in comments (shorthand)")
+	flag.Var(&ignorePatterns, "ignore-pattern", "Pattern to ignore in comments, repeatable; prefix with 're:' for a regex and 'lang=<key>:' to scope (e.g., 'lang=go:re:^TODO\\(\\w+\\):')")
+	flag.Var(&ignorePatterns, "i", "Pattern to ignore in comments, repeatable (shorthand)")
 	flag.StringVar(&configPath, "config", "", "Path to config file (default: commenter.config.json)")
 	flag.BoolVar(&showHelp, "help", false, "Show help message")
 	flag.BoolVar(&showHelp, "h", false, "Show help message (shorthand)")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
 	flag.BoolVar(&showVersion, "v", false, "Show version information (shorthand)")
+	flag.BoolVar(&listPragmas, "list-pragmas", false, "List the built-in linter and compiler pragmas that are always preserved")
 	flag.BoolVar(&removeSingleLineMultiline, "remove-single-multiline", false, "Remove single-line comments using multi-line patterns (e.g., /* comment */)")
 	flag.BoolVar(&removeSingleLineMultiline, "m", false, "Remove single-line comments using multi-line patterns (shorthand)")
+	flag.BoolVar(&keepDocComments, "keep-doc-comments", false, "Keep documentation comments directly above declarations (Go doc, JSDoc/TSDoc, C# XML docs, PHPDoc, Doxygen, Javadoc, KDoc)")
+	flag.BoolVar(&removeDocComments, "remove-doc-comments", false, "Remove doc comments that a language keeps by default (Rust ///, //!, /** */, /*! */)")
+	flag.BoolVar(&stripDirectives, "strip-directives", false, "Also remove compiler directives and build tags (e.g., //go:build, //nolint)")
+	flag.BoolVar(&reportUnusedDirectives, "report-unused-directives", false, "Warn about commenter:disable/keep directives that protected nothing")
+	flag.BoolVar(&followSymlinks, "follow-symlinks", false, "Write through symlinks to their target instead of skipping them")
+	flag.BoolVar(&showDiff, "diff", false, "Show a unified diff of the changes for each file")
+	flag.StringVar(&patchFile, "patch", "", "Write a combined unified diff of all changes to the given file (for git apply)")
+	flag.BoolVar(&check, "check", false, "Check for removable comments without modifying files (exit 0: clean, 1: comments found, 2: errors)")
+	flag.StringVar(&format, "format", FormatText, "Output format: "+formatList())
+	flag.IntVar(&jobs, "jobs", 0, "Number of files to process in parallel (default: GOMAXPROCS)")
+	flag.IntVar(&jobs, "j", 0, "Number of files to process in parallel (shorthand)")
+	flag.BoolVar(&processMinified, "process-minified", false, "Process minified files and single-line bundles instead of skipping them")
+	flag.StringVar(&stdinFilename, "stdin-filename", "", "Path used to pick the language and name the file when reading from stdin ('-')")
+	flag.StringVar(&langKey, "lang", "", "Language key for source read from stdin ('-'), e.g. typescript or go")
+	flag.BoolVar(&removeBlocks, "remove-blocks", false, "Remove all block comments, including multi-line and inline ones (e.g., foo(/* a */ b))")
+	flag.BoolVar(&removeIfZero, "remove-if-zero", false, "Treat C/C++ #if 0 ... #endif blocks as comments and remove them")
+	flag.BoolVar(&removeDocstrings, "remove-docstrings", false, "Remove docstrings (Python string statements that open a module, class or function)")
 	flag.Parse()
 
+	// flag stops at the first non-flag argument, so the flags after "-" in
+	// "commenter - --stdin-filename src/a.ts" need a second pass.
+	filterMode := flag.Arg(0) == stdinPath
+	if filterMode {
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
 	var excludeGlobs []string
 	if excludePatterns != "" {
 		excludeGlobs = strings.Split(excludePatterns, ",")
@@ -154,13 +221,9 @@ func main() {
 		}
 	}
 
-	var ignoreGlobs []string
-	if ignorePatterns != "" {
-		ignoreGlobs = strings.Split(ignorePatterns, ",")
-		for i, pattern := range ignoreGlobs {
-			ignoreGlobs[i] = strings.TrimSpace(pattern)
-		}
-	}
+	// Each -i is one pattern, commas included, so regular expressions such
+	// as ^x{1,3}$ arrive whole.
+	ignoreGlobs := []string(ignorePatterns)
 
 	if configPath == "" {
 		configPath = "commenter.config.json"
@@ -171,9 +234,36 @@ func main() {
 	}
 
 	options := mergeConfigWithFlags(cfg, write, noColor, recursive, consecutive, noWarnLarge, removeSingleLineMultiline, excludeGlobs, ignoreGlobs)
+	options.RemoveBlocks = removeBlocks
+	options.RemoveDocstrings = removeDocstrings
+	options.KeepDocComments = keepDocComments
+	options.RemoveDocComments = removeDocComments
+	options.RemoveIfZero = removeIfZero
+	options.StripDirectives = stripDirectives
+	options.ReportUnusedDirectives = reportUnusedDirectives
+	options.FollowSymlinks = followSymlinks
+	options.Diff = showDiff
+	options.PatchFile = patchFile
+	options.Check = check
+	options.Format = format
+	options.Jobs = jobs
+	options.ProcessMinified = processMinified
+	if check {
+		options.Write = false
+	}
 
 	useColor := !options.NoColor && isTerminal()
 
+	if _, err := remover.CompileIgnorePatterns(options.IgnorePatterns); err != nil {
+		printError(useColor, "%v", err)
+		os.Exit(1)
+	}
+
+	if err := checkOutputFormat(options.Format); err != nil {
+		printError(useColor, "%v", err)
+		os.Exit(1)
+	}
+
 	if showVersion {
 		fmt.Printf("%s version %s\n", filepath.Base(os.Args[0]), getVersionFromPackageJSON())
 		fmt.Printf("Built: %s\n", date)
@@ -186,6 +276,16 @@ func main() {
 		os.Exit(0)
 	}
 
+	if listPragmas {
+		printPragmaCatalog(useColor, options)
+		os.Exit(0)
+	}
+
+	if filterMode {
+		reportOutput = os.Stderr
+		os.Exit(runFilter(os.Stdin, os.Stdout, stdinFilename, langKey, options, startTime))
+	}
+
 	var inputPath string
 	if flag.NArg() < 1 {
 		inputPath = "."
@@ -193,25 +293,53 @@ func main() {
 		inputPath = flag.Arg(0)
 	}
 
-	files, err := DiscoverFiles(inputPath, options.Recursive, options.ExcludePatterns)
+	errorExitCode := 1
+	if options.Check {
+		errorExitCode = 2
+	}
+
+	files, err := remover.DiscoverFiles(inputPath, options.Recursive, options.ExcludePatterns)
 	if err != nil {
 		printError(useColor, "%v", err)
-		os.Exit(1)
+		os.Exit(errorExitCode)
 	}
 
 	if len(files) == 0 {
 		printError(useColor, "No supported files found in '%s'", inputPath)
-		os.Exit(1)
+		os.Exit(errorExitCode)
 	}
 
 	duration := time.Since(startTime)
 
 	stats := ProcessMultipleFiles(files, options, duration)
 
+	if !options.textOutput() {
+		if err := writeReport(os.Stdout, options, stats); err != nil {
+			printError(useColor, "%v", err)
+			os.Exit(errorExitCode)
+		}
+		if options.Check {
+			os.Exit(checkExitCode(stats))
+		}
+		if len(stats.Errors) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if options.Check {
+		for _, err := range stats.Errors {
+			printError(useColor, "%s", err)
+		}
+		os.Exit(checkExitCode(stats))
+	}
+
 	if len(files) == 1 {
 		if options.Write {
-			printSuccess(useColor, "File updated successfully!")
-		} else {
+			if stats.SuccessfulWrites > 0 {
+				printSuccess(useColor, "File updated successfully!")
+			}
+		} else if stats.FilesProcessed > 0 {
 			fmt.Printf("\n%sRun with --write to apply changes to the file.%s\n",
 				colorize(useColor, ColorCyan),
 				colorize(useColor, ColorReset))
@@ -0,0 +1,2088 @@
+package remover
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetLanguageByExtension(t *testing.T) {
+	tests := []struct {
+		filename     string
+		expectedLang string
+		supported    bool
+	}{
+		{
+			filename:     "main.go",
+			expectedLang: "Go",
+			supported:    true,
+		},
+		{
+			filename:     "script.js",
+			expectedLang: "TypeScript/JavaScript",
+			supported:    true,
+		},
+		{
+			filename:     "component.tsx",
+			expectedLang: "TypeScript/JavaScript",
+			supported:    true,
+		},
+		{
+			filename:     "query.sql",
+			expectedLang: "SQL",
+			supported:    true,
+		},
+		{
+			filename:     "config.json",
+			expectedLang: "JSON",
+			supported:    true,
+		},
+		{
+			filename:     "script.php",
+			expectedLang: "PHP",
+			supported:    true,
+		},
+		{
+			filename:     "template.phtml",
+			expectedLang: "PHP",
+			supported:    true,
+		},
+		{
+			filename:     "Program.cs",
+			expectedLang: "C#",
+			supported:    true,
+		},
+		{
+			filename:     "app.py",
+			expectedLang: "Python",
+			supported:    true,
+		},
+		{
+			filename:     "lib.rs",
+			expectedLang: "Rust",
+			supported:    true,
+		},
+		{
+			filename:     "deploy.sh",
+			expectedLang: "Shell",
+			supported:    true,
+		},
+		{
+			filename:     "widget.hpp",
+			expectedLang: "C/C++",
+			supported:    true,
+		},
+		{
+			filename:     "Main.java",
+			expectedLang: "Java",
+			supported:    true,
+		},
+		{
+			filename:     "build.gradle.kts",
+			expectedLang: "Kotlin",
+			supported:    true,
+		},
+		{
+			filename:     "README.md",
+			expectedLang: "",
+			supported:    false,
+		},
+		{
+			filename:     "no_extension",
+			expectedLang: "",
+			supported:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			lang, supported := GetLanguageByExtension(tt.filename)
+
+			if supported != tt.supported {
+				t.Errorf("Expected supported=%v, got %v", tt.supported, supported)
+			}
+
+			if tt.supported {
+				if lang == nil {
+					t.Fatal("Expected language object, got nil")
+				}
+				if lang.Name != tt.expectedLang {
+					t.Errorf("Expected language %q, got %q", tt.expectedLang, lang.Name)
+				}
+			} else {
+				if lang != nil {
+					t.Errorf("Expected nil language for unsupported file, got %v", lang)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveSingleLineComment(t *testing.T) {
+	lang := Language{
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	tests := []struct {
+		name               string
+		input              string
+		inMultiLineComment bool
+		expectedOutput     string
+		expectedRemoved    bool
+	}{
+		{
+			name:               "standalone comment",
+			input:              "// This is a comment",
+			inMultiLineComment: false,
+			expectedOutput:     "",
+			expectedRemoved:    true,
+		},
+		{
+			name:               "inline comment",
+			input:              `fmt.Println("Hello") // This is a comment`,
+			inMultiLineComment: false,
+			expectedOutput:     `fmt.Println("Hello")`,
+			expectedRemoved:    true,
+		},
+		{
+			name:               "no comment",
+			input:              `fmt.Println("Hello")`,
+			inMultiLineComment: false,
+			expectedOutput:     `fmt.Println("Hello")`,
+			expectedRemoved:    false,
+		},
+		{
+			name:               "comment in string literal",
+			input:              `fmt.Println("Hello // World")`,
+			inMultiLineComment: false,
+			expectedOutput:     `fmt.Println("Hello // World")`,
+			expectedRemoved:    false,
+		},
+		{
+			name:               "inside multi-line comment",
+			input:              "// This comment is inside a multi-line block",
+			inMultiLineComment: true,
+			expectedOutput:     "// This comment is inside a multi-line block",
+			expectedRemoved:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, removed := RemoveSingleLineComment(tt.input, lang, tt.inMultiLineComment, false, false)
+			if output != tt.expectedOutput {
+				t.Errorf("Expected output %q, got %q", tt.expectedOutput, output)
+			}
+			if removed != tt.expectedRemoved {
+				t.Errorf("Expected removed %v, got %v", tt.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestUpdateMultiLineCommentState(t *testing.T) {
+	lang := Language{
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	tests := []struct {
+		name         string
+		input        string
+		currentState bool
+		expected     bool
+	}{
+		{
+			name:         "start multi-line comment",
+			input:        "/* This starts a comment",
+			currentState: false,
+			expected:     true,
+		},
+		{
+			name:         "end multi-line comment",
+			input:        "This ends a comment */",
+			currentState: true,
+			expected:     false,
+		},
+		{
+			name:         "complete multi-line comment",
+			input:        "/* Complete comment */",
+			currentState: false,
+			expected:     false,
+		},
+		{
+			name:         "no comment markers",
+			input:        "Regular code line",
+			currentState: false,
+			expected:     false,
+		},
+		{
+			name:         "continue in comment",
+			input:        "Still in comment",
+			currentState: true,
+			expected:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UpdateMultiLineCommentState(tt.input, lang, tt.currentState)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v for input %q with state %v", tt.expected, result, tt.input, tt.currentState)
+			}
+		})
+	}
+}
+
+func TestJSXCommentDetection(t *testing.T) {
+	lang := Language{
+		Name:            "TypeScript/JavaScript",
+		Extensions:      []string{".ts", ".tsx", ".js", ".jsx"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		AdditionalMultiLinePatterns: []MultiLinePattern{
+			{Start: "{/*", End: "*/}"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		input        string
+		currentState bool
+		expected     bool
+	}{
+		{
+			name:         "start JSX comment",
+			input:        "  {/* This is a JSX comment",
+			currentState: false,
+			expected:     true,
+		},
+		{
+			name:         "end JSX comment",
+			input:        "This ends a JSX comment */}",
+			currentState: true,
+			expected:     false,
+		},
+		{
+			name:         "complete JSX comment",
+			input:        "  {/* Complete JSX comment */}",
+			currentState: false,
+			expected:     false,
+		},
+		{
+			name:         "JSX comment with special chars",
+			input:        "  {/* Comment with -- // *** */}",
+			currentState: false,
+			expected:     false,
+		},
+		{
+			name:         "continue in JSX comment",
+			input:        "Still in JSX comment",
+			currentState: true,
+			expected:     true,
+		},
+		{
+			name:         "mixed JSX and regular comments",
+			input:        "  {/* JSX comment */} /* Regular comment",
+			currentState: false,
+			expected:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UpdateMultiLineCommentState(tt.input, lang, tt.currentState)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v for input %q with state %v", tt.expected, result, tt.input, tt.currentState)
+			}
+		})
+	}
+}
+
+func TestIsInsideStringLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		pos      int
+		expected bool
+	}{
+		{
+			name:     "outside string",
+			line:     `fmt.Println("Hello") // comment`,
+			pos:      21,
+			expected: false,
+		},
+		{
+			name:     "inside double quotes",
+			line:     `fmt.Println("Hello // World")`,
+			pos:      19,
+			expected: true,
+		},
+		{
+			name:     "inside single quotes",
+			line:     `char := '//' // comment`,
+			pos:      10,
+			expected: true,
+		},
+		{
+			name:     "inside backticks",
+			line:     "msg := `Hello // World` // comment",
+			pos:      14,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsInsideStringLiteral(tt.line, tt.pos)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v for line %q at position %d", tt.expected, result, tt.line, tt.pos)
+			}
+		})
+	}
+}
+
+func TestLexerCarriesStringStateAcrossLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		langKey  string
+		lines    []string
+		expected []bool
+	}{
+		{
+			name:    "go raw string",
+			langKey: "go",
+			lines: []string{
+				"query := `SELECT *",
+				"  // not a comment",
+				"  FROM users` // comment",
+			},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:    "typescript template literal",
+			langKey: "typescript",
+			lines: []string{
+				"const url = `https://example.com",
+				"  // still inside ${name.replace(\"`\", \"\")}",
+				"`; // comment",
+			},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:    "sql dollar quoted body",
+			langKey: "sql",
+			lines: []string{
+				"CREATE FUNCTION f() RETURNS int AS $$",
+				"  -- part of the body",
+				"$$ LANGUAGE sql; -- comment",
+			},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:    "single-line string does not leak",
+			langKey: "go",
+			lines: []string{
+				`s := "unterminated`,
+				"// comment",
+			},
+			expected: []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := newLexer(supportedLanguages[tt.langKey])
+			for i, line := range tt.lines {
+				_, hasComment := lineCommentToken(lex.scanLine(line))
+				if hasComment != tt.expected[i] {
+					t.Errorf("line %d %q: expected comment=%v, got %v", i+1, line, tt.expected[i], hasComment)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFile_MultiLineStrings(t *testing.T) {
+	content := "const sql = `\n  SELECT 1 // keep\n`; // remove\nconst b = 2;\n"
+
+	tmpFile, err := os.CreateTemp("", "test_*.ts")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ProcessFile(tmpFile.Name(), supportedLanguages["typescript"], Options{})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	expected := []string{"const sql = `", "  SELECT 1 // keep", "`;", "const b = 2;"}
+	if !reflect.DeepEqual(result.ModifiedLines, expected) {
+		t.Errorf("Expected %q, got %q", expected, result.ModifiedLines)
+	}
+	if result.CommentsRemoved != 1 {
+		t.Errorf("Expected 1 comment removed, got %d", result.CommentsRemoved)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	src := []byte("package a\n\n// gone\nvar x = 1 // inline\n")
+	original := string(src)
+
+	result, err := Remove(src, supportedLanguages["go"], Options{})
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if string(src) != original {
+		t.Errorf("Remove modified its input: %q", src)
+	}
+	if expected := "package a\n\nvar x = 1\n"; string(result.Content()) != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Content())
+	}
+	if result.CommentsRemoved != 2 || len(result.Edits) != 2 {
+		t.Errorf("Expected 2 comments and 2 edits, got %d and %d", result.CommentsRemoved, len(result.Edits))
+	}
+
+	if _, err := Remove(src, supportedLanguages["go"], Options{IgnorePatterns: []string{"re:("}}); err == nil {
+		t.Error("Expected an error for an invalid ignore pattern")
+	}
+	bundle := []byte("var a = 1; // x " + strings.Repeat("y", minifiedMinSize))
+	if _, err := Remove(bundle, supportedLanguages["typescript"], Options{SkipMinified: true}); !errors.Is(err, ErrMinified) {
+		t.Errorf("Expected ErrMinified, got %v", err)
+	}
+	result, err = Remove(bundle, supportedLanguages["typescript"], Options{})
+	if err != nil {
+		t.Fatalf("Expected the zero Options to process minified input, got %v", err)
+	}
+	if result.CommentsRemoved != 1 {
+		t.Errorf("Expected 1 comment removed from minified input, got %d", result.CommentsRemoved)
+	}
+}
+
+func TestProcessFile(t *testing.T) {
+	content := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello")
+	/* This is a multi-line comment
+	   // This should NOT be removed
+	   End of multi-line comment */
+	fmt.Println("String with // comment inside")
+}
+`
+
+	tmpFile, err := os.CreateTemp("", "test_*.go")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := Language{
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	if result.OriginalLines != 11 {
+		t.Errorf("Expected 11 original lines, got %d", result.OriginalLines)
+	}
+
+	if result.CommentsRemoved != 0 {
+		t.Errorf("Expected 0 comments removed, got %d", result.CommentsRemoved)
+	}
+
+	expectedRemovedLines := []int{}
+	actualRemovedLines := make([]int, len(result.RemovedComments))
+	for i, comment := range result.RemovedComments {
+		actualRemovedLines[i] = comment.LineNumber
+	}
+
+	if !reflect.DeepEqual(expectedRemovedLines, actualRemovedLines) {
+		t.Errorf("Expected removed lines %v, got %v", expectedRemovedLines, actualRemovedLines)
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
+		t.Error("Multi-line comment should be preserved")
+	}
+	if !strings.Contains(modifiedContent, "// This should NOT be removed") {
+		t.Error("Comment inside multi-line comment should be preserved")
+	}
+	if !strings.Contains(modifiedContent, `"String with // comment inside"`) {
+		t.Error("Comment inside string should be preserved")
+	}
+}
+
+func TestProcessFile_KeepsLiteralRemoveLineText(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_*.go")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	content := "REMOVE_LINE\n// gone\nx := 1 // also gone\nREMOVE_LINE"
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ProcessFile(tmpFile.Name(), supportedLanguages["go"], Options{})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	expected := "REMOVE_LINE\nx := 1\nREMOVE_LINE"
+	if string(result.Content()) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(result.Content()))
+	}
+	if !reflect.DeepEqual(result.LineMap, []int{0, -1, 1, 2}) {
+		t.Errorf("Unexpected line map: %v", result.LineMap)
+	}
+}
+
+func TestProcessFile_PreservesLineEndings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "crlf with bom and trailing newline",
+			input:    "\xEF\xBB\xBFusing System;\r\n// remove me\r\nvar x = 1; // inline\r\n",
+			expected: "\xEF\xBB\xBFusing System;\r\nvar x = 1;\r\n",
+		},
+		{
+			name:     "mixed endings without trailing newline",
+			input:    "a();\r\nb();\n// gone\r\nc(); // inline",
+			expected: "a();\r\nb();\nc();",
+		},
+		{
+			name:     "removed last line keeps missing trailing newline",
+			input:    "a();\r\n// gone",
+			expected: "a();",
+		},
+		{
+			name:     "untouched file is byte identical",
+			input:    "\xEF\xBB\xBFa();\r\n\r\nb();\n  \r\n",
+			expected: "\xEF\xBB\xBFa();\r\n\r\nb();\n  \r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "test_*.cs")
+			if err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+			defer os.Remove(tmpFile.Name())
+
+			if _, err := tmpFile.WriteString(tt.input); err != nil {
+				t.Fatalf("Failed to write to temp file: %v", err)
+			}
+			tmpFile.Close()
+
+			result, err := ProcessFile(tmpFile.Name(), supportedLanguages["csharp"], Options{})
+			if err != nil {
+				t.Fatalf("ProcessFile failed: %v", err)
+			}
+
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestProcessFile_Edits(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_edits_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "a.ts")
+	content := "\uFEFFconst 😀 = 'é'; // note\r\n// gone\r\n// also gone\r\nlet b = 2;\r\n// tail"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := ProcessFile(path, supportedLanguages["typescript"], Options{Consecutive: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	expected := []Edit{
+		{
+			Start: Position{Offset: 21, Line: 1, Column: 19, RuneColumn: 15, UTF16Column: 16},
+			End:   Position{Offset: 29, Line: 1, Column: 27, RuneColumn: 23, UTF16Column: 24},
+			Kind:  CommentInline,
+		},
+		{
+			Start:   Position{Offset: 31, Line: 2, Column: 1, RuneColumn: 1, UTF16Column: 1},
+			End:     Position{Offset: 40, Line: 3, Column: 1, RuneColumn: 1, UTF16Column: 1},
+			Kind:    CommentStandalone,
+			Comment: 1,
+		},
+		{
+			Start:   Position{Offset: 40, Line: 3, Column: 1, RuneColumn: 1, UTF16Column: 1},
+			End:     Position{Offset: 54, Line: 4, Column: 1, RuneColumn: 1, UTF16Column: 1},
+			Kind:    CommentStandalone,
+			Comment: 2,
+		},
+		{
+			Start:   Position{Offset: 64, Line: 4, Column: 11, RuneColumn: 11, UTF16Column: 11},
+			End:     Position{Offset: 73, Line: 5, Column: 8, RuneColumn: 8, UTF16Column: 8},
+			Kind:    CommentStandalone,
+			Comment: 3,
+		},
+	}
+	if !reflect.DeepEqual(result.Edits, expected) {
+		t.Errorf("Expected edits:\n%+v\ngot:\n%+v", expected, result.Edits)
+	}
+
+	applied := content
+	for i := len(result.Edits) - 1; i >= 0; i-- {
+		edit := result.Edits[i]
+		applied = applied[:edit.Start.Offset] + edit.Replacement + applied[edit.End.Offset:]
+	}
+	if applied != string(result.Content()) {
+		t.Errorf("Applying edits gave %q, Content() gave %q", applied, result.Content())
+	}
+	if want := "\uFEFFconst 😀 = 'é';\r\nlet b = 2;"; applied != want {
+		t.Errorf("Expected %q, got %q", want, applied)
+	}
+	if !reflect.DeepEqual(result.LineMap, []int{0, -1, -1, 1, -1}) {
+		t.Errorf("Unexpected line map %v", result.LineMap)
+	}
+}
+
+func TestDiscoverFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test_discover_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create test files
+	testFiles := map[string]string{
+		"test.go":  "package main\n// comment\nfunc main() {}",
+		"test.js":  "// comment\nconsole.log('hello');",
+		"test.sql": "-- comment\nSELECT * FROM users;",
+		"test.txt": "This should be ignored",
+	}
+
+	for filename, content := range testFiles {
+		filePath := tempDir + "/" + filename
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", filename, err)
+		}
+	}
+
+	files, err := DiscoverFiles(tempDir, false, []string{})
+	if err != nil {
+		t.Fatalf("DiscoverFiles failed: %v", err)
+	}
+
+	expectedCount := 3 // .go, .js, .sql files
+	if len(files) != expectedCount {
+		t.Errorf("Expected %d files, got %d", expectedCount, len(files))
+	}
+
+	// Check that we got the right file types
+	extensions := make(map[string]bool)
+	for _, file := range files {
+		for _, ext := range file.Language.Extensions {
+			extensions[ext] = true
+		}
+	}
+
+	expectedExtensions := []string{".go", ".js", ".sql"}
+	for _, ext := range expectedExtensions {
+		if !extensions[ext] {
+			t.Errorf("Expected to find files with extension %s", ext)
+		}
+	}
+}
+
+func TestRemoveSingleLineMultilineComment(t *testing.T) {
+	lang := Language{
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	// Single-line multi-line comment
+	line := "   /* This is a single-line multi-line comment */   "
+	removed, content := RemoveSingleLineMultilineComment(line, lang)
+	if !removed {
+		t.Errorf("Expected single-line multi-line comment to be removed")
+	}
+	if content != line {
+		t.Errorf("Expected content to match original line")
+	}
+
+	// Not a single-line multi-line comment (just the markers)
+	line2 := "/* */"
+	removed2, _ := RemoveSingleLineMultilineComment(line2, lang)
+	if removed2 {
+		t.Errorf("Did not expect just the markers to be removed as single-line comment")
+	}
+}
+
+func TestProcessFile_RemoveSingleLineMultiline(t *testing.T) {
+	content := `package main
+
+func main() {
+	fmt.Println("Hello")
+	/* This is a multi-line comment
+	   // This should NOT be removed
+	   End of multi-line comment */
+	fmt.Println("String with // comment inside")
+	/* Single-line multi-line comment */
+}`
+
+	tmpFile, err := os.CreateTemp("", "test_*.go")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := Language{
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{RemoveSingleLineMultiline: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	if result.CommentsRemoved != 1 {
+		t.Errorf("Expected 1 comment removed, got %d", result.CommentsRemoved)
+	}
+
+	for _, comment := range result.RemovedComments {
+		if !strings.Contains(comment.Content, "Single-line multi-line comment") {
+			t.Errorf("Expected removed comment to be the single-line multi-line comment, got: %s", comment.Content)
+		}
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	if strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
+		t.Error("Single-line multi-line comment should be removed")
+	}
+}
+
+func TestProcessFile_RemoveBlocks(t *testing.T) {
+	content := `const a = 1; /* trailing
+   block */ const b = 2;
+/**
+ * Doc block
+ */
+function foo(/* a */ b, c /* c */) {
+  return <div>
+    {/* JSX comment */}
+    {/*
+      multi-line JSX
+    */}
+  </div>; // inline
+}
+/* @keep this one */`
+
+	tmpFile, err := os.CreateTemp("", "test_*.tsx")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	options := Options{RemoveBlocks: true, IgnorePatterns: []string{"@keep"}}
+	result, err := ProcessFile(tmpFile.Name(), supportedLanguages["typescript"], options)
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	expected := []string{
+		"const a = 1;",
+		"const b = 2;",
+		"function foo(b, c) {",
+		"  return <div>",
+		"  </div>;",
+		"}",
+		"/* @keep this one */",
+	}
+	if !reflect.DeepEqual(result.ModifiedLines, expected) {
+		t.Errorf("Expected lines:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(result.ModifiedLines, "\n"))
+	}
+
+	expectedRanges := [][2]int{{1, 2}, {3, 5}, {6, 6}, {6, 6}, {8, 8}, {9, 11}, {12, 12}}
+	if len(result.RemovedComments) != len(expectedRanges) {
+		t.Fatalf("Expected %d removed comments, got %d: %+v", len(expectedRanges), len(result.RemovedComments), result.RemovedComments)
+	}
+	for i, r := range expectedRanges {
+		comment := result.RemovedComments[i]
+		if comment.LineNumber != r[0] || comment.EndLineNumber != r[1] {
+			t.Errorf("Comment %d: expected lines %d-%d, got %d-%d", i, r[0], r[1], comment.LineNumber, comment.EndLineNumber)
+		}
+	}
+}
+
+func TestRemoveBlocks_BracesInCode(t *testing.T) {
+	lang := supportedLanguages["typescript"]
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "empty function body",
+			input:    "function f() {/* noop */}\n",
+			expected: "function f() {}\n",
+		},
+		{
+			name:     "empty object",
+			input:    "const o = {/* empty */};\n",
+			expected: "const o = {};\n",
+		},
+		{
+			name:     "multi-line comment in block",
+			input:    "if (x) {/* a\n b */}\n",
+			expected: "if (x) {\n}\n",
+		},
+		{
+			name:     "arrow function body on its own line",
+			input:    "const f = () =>\n  {/* noop */}\n",
+			expected: "const f = () =>\n  {}\n",
+		},
+		{
+			name:     "JSX child",
+			input:    "const a = <div>\n  {/* child */}\n  <b />\n</div>;\n",
+			expected: "const a = <div>\n  <b />\n</div>;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(tt.input), lang, Options{RemoveBlocks: true})
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestProcessFile_KeepDocComments(t *testing.T) {
+	tests := []struct {
+		name      string
+		langKey   string
+		ext       string
+		content   string
+		preserved []string
+		removed   []string
+	}{
+		{
+			name:    "go exported declarations",
+			langKey: "go",
+			ext:     ".go",
+			content: `// Package demo does things.
+package demo
+
+// Exported is documented.
+// It spans two lines.
+func Exported() {}
+
+// unexported helper
+func helper() {}
+
+// Detached comment
+
+type Config struct{}
+
+// Limits of the demo.
+const (
+	// MaxSize caps the buffer.
+	MaxSize = 10
+)
+
+// Shared state.
+var(
+	Verbose bool
+)`,
+			preserved: []string{"// Package demo does things.", "// Exported is documented.", "// It spans two lines.", "// Limits of the demo.", "// MaxSize caps the buffer.", "// Shared state."},
+			removed:   []string{"// unexported helper", "// Detached comment"},
+		},
+		{
+			name:    "jsdoc",
+			langKey: "typescript",
+			ext:     ".ts",
+			content: `/**
+ * Adds numbers.
+ */
+export function add(a: number, b: number) {
+  /** not a doc, nothing follows */
+
+  // regular comment
+  return a + b;
+}`,
+			preserved: []string{"* Adds numbers."},
+			removed:   []string{"// regular comment", "/** not a doc"},
+		},
+		{
+			name:    "csharp xml docs",
+			langKey: "csharp",
+			ext:     ".cs",
+			content: `/// <summary>Greets.</summary>
+[Obsolete]
+public void Greet() {
+    //// banner, not a doc
+    Console.WriteLine("hi");
+}`,
+			preserved: []string{"/// <summary>Greets.</summary>"},
+			removed:   []string{"//// banner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "test_*"+tt.ext)
+			if err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+			defer os.Remove(tmpFile.Name())
+
+			if _, err := tmpFile.WriteString(tt.content); err != nil {
+				t.Fatalf("Failed to write to temp file: %v", err)
+			}
+			tmpFile.Close()
+
+			options := Options{KeepDocComments: true, RemoveBlocks: true}
+			result, err := ProcessFile(tmpFile.Name(), supportedLanguages[tt.langKey], options)
+			if err != nil {
+				t.Fatalf("ProcessFile failed: %v", err)
+			}
+
+			modifiedContent := strings.Join(result.ModifiedLines, "\n")
+			for _, comment := range tt.preserved {
+				if !strings.Contains(modifiedContent, comment) {
+					t.Errorf("Expected doc comment to be preserved: %s", comment)
+				}
+			}
+			for _, comment := range tt.removed {
+				if strings.Contains(modifiedContent, comment) {
+					t.Errorf("Expected comment to be removed: %s", comment)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFile_GoDirectives(t *testing.T) {
+	content := `//go:build linux && !race
+// +build linux,!race
+
+package main
+
+/*
+#include <stdio.h>
+*/
+// #cgo LDFLAGS: -lm
+import "C"
+
+//go:generate stringer -type=Kind
+// Regular comment
+
+//go:embed static
+var static embed.FS
+
+func main() {
+	_ = run() //nolint:errcheck
+	x := 1 // plain inline comment
+}`
+
+	tmpFile, err := os.CreateTemp("", "test_*.go")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := supportedLanguages["go"]
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{Consecutive: true, RemoveBlocks: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	for _, directive := range []string{"//go:build linux", "// +build linux", "#include <stdio.h>", "// #cgo LDFLAGS", "//go:generate", "//go:embed static", "//nolint:errcheck"} {
+		if !strings.Contains(modifiedContent, directive) {
+			t.Errorf("Expected directive to be preserved: %s", directive)
+		}
+	}
+	if result.CommentsRemoved != 2 {
+		t.Errorf("Expected 2 comments removed, got %d", result.CommentsRemoved)
+	}
+
+	stripped, err := ProcessFile(tmpFile.Name(), lang, Options{Consecutive: true, RemoveBlocks: true, StripDirectives: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+	if strippedContent := strings.Join(stripped.ModifiedLines, "\n"); strings.Contains(strippedContent, "//") || strings.Contains(strippedContent, "/*") {
+		t.Errorf("Expected all comments to be removed with StripDirectives, got:\n%s", strippedContent)
+	}
+
+	if output, removed := RemoveSingleLineComment("//go:generate go run gen.go", lang, false, false, false); removed {
+		t.Errorf("Expected RemoveSingleLineComment to keep directive, got %q", output)
+	}
+}
+
+func TestPHPCommentRemoval(t *testing.T) {
+	content := `<?php
+// This is a single-line comment
+/* This is a multi-line comment
+   that spans multiple lines */
+
+class Example {
+    // Class property comment
+    private $name = "test";
+    
+    /* Single-line multi-line comment */
+    
+    public function __construct() {
+        // Constructor comment
+        echo "Hello World"; // Inline comment
+    }
+}`
+
+	tmpFile, err := os.CreateTemp("", "test_*.php")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := Language{
+		Name:            "PHP",
+		Extensions:      []string{".php", ".phtml"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	if result.CommentsRemoved != 4 {
+		t.Errorf("Expected 4 comments removed, got %d", result.CommentsRemoved)
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
+		t.Error("Multi-line comment should be preserved")
+	}
+	if !strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
+		t.Error("Single-line multi-line comment should be preserved when flag is false")
+	}
+}
+
+func TestCSharpCommentRemoval(t *testing.T) {
+	content := `using System;
+
+// This is a single-line comment
+/* This is a multi-line comment
+   that spans multiple lines */
+
+namespace Example
+{
+    // Class comment
+    public class Program
+    {
+        // Property comment
+        public string Name { get; set; }
+        
+        /* Single-line multi-line comment */
+        
+        public void Test()
+        {
+            string message = "String with // comment inside";
+            Console.WriteLine(message);
+        }
+    }
+}`
+
+	tmpFile, err := os.CreateTemp("", "test_*.cs")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := Language{
+		Name:            "C#",
+		Extensions:      []string{".cs"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{RemoveSingleLineMultiline: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	if result.CommentsRemoved != 4 {
+		t.Errorf("Expected 4 comments removed, got %d", result.CommentsRemoved)
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+	if !strings.Contains(modifiedContent, "/* This is a multi-line comment") {
+		t.Error("Multi-line comment should be preserved")
+	}
+	if strings.Contains(modifiedContent, "/* Single-line multi-line comment */") {
+		t.Error("Single-line multi-line comment should be removed when flag is true")
+	}
+}
+
+func TestPythonCommentRemoval(t *testing.T) {
+	lang := supportedLanguages["python"]
+	input := `#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+"""Module docstring."""
+import os  # noqa: F401
+from typing import List  # type: ignore
+
+# standalone comment
+n = 1  # Return type: int
+# see noqa docs
+m = 2  # explained  # noqa: E501
+# pylint: disable=invalid-name
+# after a pragma
+x = "# not a comment"  # trailing
+y = f"{x!r} # nope {{literal}}"  # real
+z = r'\d+ # raw'  # raw trailing
+s = """
+# inside triple
+"""  # after triple
+
+
+def f(
+    a,  # arg comment
+) -> List[int]:
+    """Docstring of f.
+
+    More text.
+    """
+    return [a]
+
+
+class Empty:
+    """Only a docstring."""
+
+
+def g(): return 1
+"""not a docstring"""
+`
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:    "comments only",
+			options: Options{},
+			expected: `#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+"""Module docstring."""
+import os  # noqa: F401
+from typing import List  # type: ignore
+
+n = 1
+m = 2  # explained  # noqa: E501
+# pylint: disable=invalid-name
+x = "# not a comment"
+y = f"{x!r} # nope {{literal}}"
+z = r'\d+ # raw'
+s = """
+# inside triple
+"""
+
+
+def f(
+    a,
+) -> List[int]:
+    """Docstring of f.
+
+    More text.
+    """
+    return [a]
+
+
+class Empty:
+    """Only a docstring."""
+
+
+def g(): return 1
+"""not a docstring"""
+`,
+		},
+		{
+			name:    "remove docstrings",
+			options: Options{RemoveDocstrings: true},
+			expected: `#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+import os  # noqa: F401
+from typing import List  # type: ignore
+
+n = 1
+m = 2  # explained  # noqa: E501
+# pylint: disable=invalid-name
+x = "# not a comment"
+y = f"{x!r} # nope {{literal}}"
+z = r'\d+ # raw'
+s = """
+# inside triple
+"""
+
+
+def f(
+    a,
+) -> List[int]:
+    return [a]
+
+
+class Empty:
+    """Only a docstring."""
+
+
+def g(): return 1
+"""not a docstring"""
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(input), lang, tt.options)
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+
+	result, err := Remove([]byte(input), lang, Options{RemoveDocstrings: true})
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	var docstrings []int
+	for _, comment := range result.RemovedComments {
+		if comment.Kind == CommentDocstring {
+			docstrings = append(docstrings, comment.LineNumber)
+		}
+	}
+	if !reflect.DeepEqual(docstrings, []int{3, 24}) {
+		t.Errorf("Expected docstrings on lines [3 24], got %v", docstrings)
+	}
+
+	if !IsInsideString(`x = """a # b`, 9, lang) || IsInsideString(`x = "a" # b`, 8, lang) {
+		t.Error("IsInsideString should follow Python string rules")
+	}
+}
+
+func TestRustCommentRemoval(t *testing.T) {
+	lang := supportedLanguages["rust"]
+	input := `//! Crate docs.
+
+/* outer /* inner */ still comment */
+/// Adds.
+#[inline]
+pub fn add<'a>(a: &'a str) -> char {
+    let s = r#"not // a "comment""#; // raw
+    let q = '"'; // quote char
+    '/' // trailing
+}
+
+// SAFETY: callers hold the lock.
+// plain
+fn y() {}
+`
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:    "keeps doc comments",
+			options: Options{Consecutive: true, RemoveBlocks: true},
+			expected: `//! Crate docs.
+
+/// Adds.
+#[inline]
+pub fn add<'a>(a: &'a str) -> char {
+    let s = r#"not // a "comment""#;
+    let q = '"';
+    '/'
+}
+
+// SAFETY: callers hold the lock.
+fn y() {}
+`,
+		},
+		{
+			name:    "remove doc comments",
+			options: Options{Consecutive: true, RemoveBlocks: true, RemoveDocComments: true},
+			expected: `
+#[inline]
+pub fn add<'a>(a: &'a str) -> char {
+    let s = r#"not // a "comment""#;
+    let q = '"';
+    '/'
+}
+
+// SAFETY: callers hold the lock.
+fn y() {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(input), lang, tt.options)
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+
+	if depth := UpdateMultiLineCommentDepth("/* a /* b */", lang, 0); depth != 1 {
+		t.Errorf("Expected depth 1 after a nested comment closes once, got %d", depth)
+	}
+	if depth := UpdateMultiLineCommentDepth("/* c", lang, 2); depth != 3 {
+		t.Errorf("Expected depth 3, got %d", depth)
+	}
+	if !IsInsideString(`let s = r##"a "# // b"##;`, 16, lang) {
+		t.Error("IsInsideString should follow Rust raw string rules")
+	}
+}
+
+func TestShellCommentRemoval(t *testing.T) {
+	lang := supportedLanguages["shell"]
+	input := `#!/usr/bin/env bash
+# shellcheck disable=SC2086
+set -euo pipefail # trailing
+
+name=${1#prefix} # strip prefix
+echo $# 'it # is' $'tab\t# x' "a ${x:-"b # c"} d" # real
+echo "$(echo "a # b")" "$((1 + 2))" # subst
+echo "` + "`" + `echo \"a # b\"` + "`" + `" # backtick
+if (( 1 << 2 )); then echo ok; fi # shift
+cat <<EOF
+# not a comment
+EOF
+cat <<-'END' | grep x # after heredoc
+	# also body
+	END
+`
+	expected := `#!/usr/bin/env bash
+# shellcheck disable=SC2086
+set -euo pipefail
+
+name=${1#prefix}
+echo $# 'it # is' $'tab\t# x' "a ${x:-"b # c"} d"
+echo "$(echo "a # b")" "$((1 + 2))"
+echo "` + "`" + `echo \"a # b\"` + "`" + `"
+if (( 1 << 2 )); then echo ok; fi
+cat <<EOF
+# not a comment
+EOF
+cat <<-'END' | grep x
+	# also body
+	END
+`
+
+	result, err := Remove([]byte(input), lang, Options{Consecutive: true})
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if content := string(result.Content()); content != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+	}
+	if result.CommentsRemoved != 7 {
+		t.Errorf("Expected 7 comments removed, got %d", result.CommentsRemoved)
+	}
+}
+
+func TestKeptLinesDoNotJoinCommentRuns(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "shell",
+			lang:     "shell",
+			input:    "#!/bin/bash\n# helper\necho\n",
+			expected: "#!/bin/bash\necho\n",
+		},
+		{
+			name:     "python",
+			lang:     "python",
+			input:    "#!/usr/bin/env python3\n# helper\nx = 1\n",
+			expected: "#!/usr/bin/env python3\nx = 1\n",
+		},
+		{
+			name:     "go build constraint",
+			lang:     "go",
+			input:    "//go:build x\n// helper\npackage a\n",
+			expected: "//go:build x\npackage a\n",
+		},
+		{
+			name:     "python pragma line",
+			lang:     "python",
+			input:    "# type: ignore\n# helper\nx = 1\n",
+			expected: "# type: ignore\nx = 1\n",
+		},
+		{
+			name:     "run after the shebang",
+			lang:     "shell",
+			input:    "#!/bin/sh\n# one\n# two\necho\n",
+			expected: "#!/bin/sh\n# one\n# two\necho\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(tt.input), supportedLanguages[tt.lang], Options{})
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestCppCommentRemoval(t *testing.T) {
+	lang := supportedLanguages["cpp"]
+	input := `#ifndef FOO_H
+#define FOO_H
+#pragma once // keep
+
+int x = 1; // trailing \
+   continued
+const char *s = R"json({"a": "// not"})json"; // raw
+char q = '"'; // quote
+int big = 1'000'000; // digits
+int y = foo(); // NOLINT
+#if 0
+dead(); // in dead code
+#endif
+#if 0
+old();
+#else
+live();
+#endif
+
+#endif // FOO_H
+`
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:    "comments only",
+			options: Options{},
+			expected: `#ifndef FOO_H
+#define FOO_H
+#pragma once // keep
+
+int x = 1;
+const char *s = R"json({"a": "// not"})json";
+char q = '"';
+int big = 1'000'000;
+int y = foo(); // NOLINT
+#if 0
+dead();
+#endif
+#if 0
+old();
+#else
+live();
+#endif
+
+#endif // FOO_H
+`,
+		},
+		{
+			name:    "remove if 0",
+			options: Options{RemoveIfZero: true},
+			expected: `#ifndef FOO_H
+#define FOO_H
+#pragma once // keep
+
+int x = 1;
+const char *s = R"json({"a": "// not"})json";
+char q = '"';
+int big = 1'000'000;
+int y = foo(); // NOLINT
+live();
+
+#endif // FOO_H
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(input), lang, tt.options)
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+
+	if !IsInsideString(`auto s = R"x(a )" // b)x";`, 18, lang) || IsInsideString(`char c = '"'; // x`, 16, lang) {
+		t.Error("IsInsideString should follow C++ raw string and character rules")
+	}
+}
+
+func TestJavaKotlinCommentRemoval(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		input    string
+		expected string
+	}{
+		{
+			name: "java text blocks",
+			lang: "java",
+			input: `/** Javadoc. */
+public class A { // class
+    String t = """
+        // in text block \"""
+        """; // after
+    char c = '"'; // quote
+    //noinspection unchecked
+    // @formatter:off
+    int x = 1; // x
+}
+`,
+			expected: `/** Javadoc. */
+public class A {
+    String t = """
+        // in text block \"""
+        """;
+    char c = '"';
+    //noinspection unchecked
+    // @formatter:off
+    int x = 1;
+}
+`,
+		},
+		{
+			name: "kotlin templates and nested comments",
+			lang: "kotlin",
+			input: `/* outer /* inner */ still */
+/**
+ * KDoc.
+ */
+fun greet(a: String, b: Int): String {
+    val s = "${a // b}" // after template
+    val r = """
+        // raw ${b}
+    """ // after raw
+    return s + r // done
+}
+`,
+			expected: `/**
+ * KDoc.
+ */
+fun greet(a: String, b: Int): String {
+    val s = "${a // b}"
+    val r = """
+        // raw ${b}
+    """
+    return s + r
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(tt.input), supportedLanguages[tt.lang], Options{RemoveBlocks: true, KeepDocComments: true})
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	content := `// This is a regular comment
+// @ts-ignore This should be preserved
+// @deprecated This should be preserved
+// TODO: This should be preserved
+// FIXME: This should be preserved
+
+function test() {
+    // Regular inline comment
+    const x = 42; // @ts-ignore inline ignore comment
+    const y = 10; // Regular inline comment
+    
+    /* Regular multi-line comment */
+    
+    /* @ts-ignore multi-line ignore comment */
+    
+    return x + y;
+}
+
+// @ts-expect-error This should be preserved too`
+
+	tmpFile, err := os.CreateTemp("", "test_*.ts")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := Language{
+		Name:            "TypeScript/JavaScript",
+		Extensions:      []string{".ts", ".tsx", ".js", ".jsx"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+	}
+
+	ignorePatterns := []string{"@ts-ignore", "@deprecated", "TODO", "FIXME", "@ts-expect-error"}
+
+	result, err := ProcessFile(tmpFile.Name(), lang, Options{RemoveSingleLineMultiline: true, IgnorePatterns: ignorePatterns})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	// Should only remove 3 comments: regular standalone comment, regular inline comment, and regular multi-line comment
+	if result.CommentsRemoved != 3 {
+		t.Errorf("Expected 3 comments removed, got %d", result.CommentsRemoved)
+	}
+
+	modifiedContent := strings.Join(result.ModifiedLines, "\n")
+
+	// Check that ignore pattern comments are preserved
+	preservedComments := []string{
+		"@ts-ignore This should be preserved",
+		"@deprecated This should be preserved",
+		"TODO: This should be preserved",
+		"FIXME: This should be preserved",
+		"@ts-ignore inline ignore comment",
+		"@ts-ignore multi-line ignore comment",
+		"@ts-expect-error This should be preserved too",
+	}
+
+	for _, comment := range preservedComments {
+		if !strings.Contains(modifiedContent, comment) {
+			t.Errorf("Expected preserved comment containing: %s", comment)
+		}
+	}
+
+	// Check that regular comments are removed
+	removedComments := []string{
+		"Regular inline comment",
+	}
+
+	for _, comment := range removedComments {
+		if strings.Contains(modifiedContent, comment) {
+			t.Errorf("Expected removed comment: %s", comment)
+		}
+	}
+}
+
+func TestShouldIgnoreComment(t *testing.T) {
+	ignorePatterns, err := CompileIgnorePatterns([]string{"@ts-ignore", "TODO", "FIXME", `re:^NOTE\(\w+\):`, "lang=sql:DBA-ONLY"})
+	if err != nil {
+		t.Fatalf("CompileIgnorePatterns failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		langKey  string
+		comment  string
+		expected bool
+	}{
+		{
+			name:     "standalone ignore comment",
+			langKey:  "typescript",
+			comment:  "// @ts-ignore This should be ignored",
+			expected: true,
+		},
+		{
+			name:     "inline ignore comment",
+			langKey:  "typescript",
+			comment:  "const x = 42; // @ts-ignore inline comment",
+			expected: true,
+		},
+		{
+			name:     "multi-line ignore comment",
+			langKey:  "typescript",
+			comment:  "/* @ts-ignore multi-line comment */",
+			expected: true,
+		},
+		{
+			name:     "TODO comment",
+			langKey:  "go",
+			comment:  "// TODO: Fix this later",
+			expected: true,
+		},
+		{
+			name:     "FIXME comment",
+			langKey:  "go",
+			comment:  "// FIXME: This needs attention",
+			expected: true,
+		},
+		{
+			name:     "regular comment",
+			langKey:  "go",
+			comment:  "// This is a regular comment",
+			expected: false,
+		},
+		{
+			name:     "SQL comment with ignore pattern",
+			langKey:  "sql",
+			comment:  "-- @ts-ignore SQL comment",
+			expected: true,
+		},
+		{
+			name:     "SQL comment without ignore pattern",
+			langKey:  "sql",
+			comment:  "-- Regular SQL comment",
+			expected: false,
+		},
+		{
+			name:     "anchored regex matches comment body",
+			langKey:  "go",
+			comment:  "x := 1 // NOTE(ann): keep this",
+			expected: true,
+		},
+		{
+			name:     "anchored regex does not match mid-body",
+			langKey:  "go",
+			comment:  "// see NOTE(ann): for details",
+			expected: false,
+		},
+		{
+			name:     "SQL comment containing double slash",
+			langKey:  "sql",
+			comment:  "SELECT 1; -- NOTE(ann): see https://example.com",
+			expected: true,
+		},
+		{
+			name:     "language scoped pattern in its language",
+			langKey:  "sql",
+			comment:  "-- DBA-ONLY: index hint",
+			expected: true,
+		},
+		{
+			name:     "language scoped pattern in another language",
+			langKey:  "go",
+			comment:  "// DBA-ONLY: index hint",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang := supportedLanguages[tt.langKey]
+			lang.Pragmas = nil
+			result := shouldIgnoreComment(tt.comment, lang, ignorePatterns)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v for comment: %s", tt.expected, result, tt.comment)
+			}
+		})
+	}
+}
+
+func TestCompileIgnorePatterns(t *testing.T) {
+	patterns, err := CompileIgnorePatterns([]string{"TODO", "lang=go:re:^nolint", "unknown:FIXME", "go:generate", "re:^x{1,3}$", ""})
+	if err != nil {
+		t.Fatalf("CompileIgnorePatterns failed: %v", err)
+	}
+
+	expected := []IgnorePattern{
+		{Text: "TODO"},
+		{Language: "go", Regexp: patterns[1].Regexp},
+		{Text: "unknown:FIXME"},
+		{Text: "go:generate"},
+		{Regexp: patterns[4].Regexp},
+	}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, patterns)
+	}
+	if patterns[1].Regexp == nil || patterns[1].Regexp.String() != "^nolint" {
+		t.Errorf("Expected compiled regexp ^nolint, got %v", patterns[1].Regexp)
+	}
+	if !patterns[3].Matches("go:generate stringer", "typescript") {
+		t.Error("Expected an unscoped go:generate pattern to match in any language")
+	}
+	if re := patterns[4].Regexp; re == nil || !re.MatchString("xxx") || re.MatchString("xxxx") {
+		t.Errorf("Expected ^x{1,3}$ to be compiled whole, got %v", re)
+	}
+
+	if _, err := CompileIgnorePatterns([]string{"re:("}); err == nil {
+		t.Error("Expected error for invalid regular expression")
+	}
+	if _, err := CompileIgnorePatterns([]string{"lang=cobol:TODO"}); err == nil {
+		t.Error("Expected error for an unknown language scope")
+	}
+}
+
+func TestPragmaCatalog(t *testing.T) {
+	content := `/// <reference path="./globals.d.ts" />
+// eslint-disable-next-line no-console
+console.log("x");
+// prettier-ignore
+const matrix = [1,0,0,1];
+// @ts-expect-error wrong type on purpose
+const n: number = "1";
+/* istanbul ignore next */
+// @custom-keep extended via config
+// plain comment`
+
+	tmpFile, err := os.CreateTemp("", "test_*.ts")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	lang := supportedLanguages["typescript"]
+
+	tests := []struct {
+		name            string
+		options         Options
+		expectedRemoved int
+	}{
+		{
+			name:            "catalog preserves pragmas",
+			options:         Options{RemoveBlocks: true, ExtraPragmas: map[string][]string{"typescript": {"@custom-keep"}}},
+			expectedRemoved: 1,
+		},
+		{
+			name:            "extra pragmas are scoped to their language",
+			options:         Options{RemoveBlocks: true, ExtraPragmas: map[string][]string{"go": {"@custom-keep"}}},
+			expectedRemoved: 2,
+		},
+		{
+			name:            "catalog can be disabled",
+			options:         Options{RemoveBlocks: true, DisablePragmas: true},
+			expectedRemoved: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Consecutive = true
+			result, err := ProcessFile(tmpFile.Name(), lang, tt.options)
+			if err != nil {
+				t.Fatalf("ProcessFile failed: %v", err)
+			}
+			if result.CommentsRemoved != tt.expectedRemoved {
+				t.Errorf("Expected %d comments removed, got %d: %+v", tt.expectedRemoved, result.CommentsRemoved, result.RemovedComments)
+			}
+		})
+	}
+
+	if len(supportedLanguages["typescript"].Pragmas) != len(lang.Pragmas) {
+		t.Error("Extending the catalog must not modify supportedLanguages")
+	}
+}
+
+func TestControlDirectives(t *testing.T) {
+	content := `// removed
+// commenter:disable
+// kept in region
+const a = 1; // kept inline
+// commenter:enable
+// commenter:keep
+const b = 2; // kept by keep
+const c = 3; // removed inline
+compute(/* kept */ 1); // commenter:keep
+// commenter:keep
+
+const d = 4;
+/* commenter:disable */`
+
+	tmpFile, err := os.CreateTemp("", "test_*.ts")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ProcessFile(tmpFile.Name(), supportedLanguages["typescript"], Options{Consecutive: true, RemoveBlocks: true})
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	removedLines := make([]int, 0, len(result.RemovedComments))
+	for _, comment := range result.RemovedComments {
+		removedLines = append(removedLines, comment.LineNumber)
+	}
+	if !reflect.DeepEqual(removedLines, []int{1, 8}) {
+		t.Errorf("Expected removed lines [1 8], got %v", removedLines)
+	}
+
+	expectedRegions := []ProtectedRegion{
+		{Directive: "disable", DirectiveLine: 2, StartLine: 2, EndLine: 5, Protected: 2},
+		{Directive: "keep", DirectiveLine: 6, StartLine: 7, EndLine: 7, Protected: 1},
+		{Directive: "keep", DirectiveLine: 9, StartLine: 9, EndLine: 9, Protected: 1},
+		{Directive: "keep", DirectiveLine: 10, StartLine: 11, EndLine: 11, Protected: 0},
+		{Directive: "disable", DirectiveLine: 13, StartLine: 13, EndLine: 13, Protected: 0},
+	}
+	if !reflect.DeepEqual(result.ProtectedRegions, expectedRegions) {
+		t.Errorf("Expected regions %+v, got %+v", expectedRegions, result.ProtectedRegions)
+	}
+
+	unused := result.UnusedDirectives()
+	if len(unused) != 2 || unused[0].DirectiveLine != 10 || unused[1].DirectiveLine != 13 {
+		t.Errorf("Expected unused directives on lines 10 and 13, got %+v", unused)
+	}
+}
+
+func TestControlDirectives_Credit(t *testing.T) {
+	content := `const d = 4; // commenter:keep
+// commenter:disable
+// kept in region
+// commenter:keep
+const e = 5; // kept by keep
+// commenter:enable
+// commenter:keep`
+
+	result, err := Remove([]byte(content), supportedLanguages["typescript"], Options{Consecutive: true})
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if string(result.Content()) != content {
+		t.Errorf("Expected every comment to be kept, got:\n%s", result.Content())
+	}
+
+	expectedRegions := []ProtectedRegion{
+		{Directive: "keep", DirectiveLine: 1, StartLine: 1, EndLine: 1, Protected: 1},
+		{Directive: "disable", DirectiveLine: 2, StartLine: 2, EndLine: 6, Protected: 1},
+		{Directive: "keep", DirectiveLine: 4, StartLine: 5, EndLine: 5, Protected: 1},
+		{Directive: "keep", DirectiveLine: 7, StartLine: 7, EndLine: 7, Protected: 0},
+	}
+	if !reflect.DeepEqual(result.ProtectedRegions, expectedRegions) {
+		t.Errorf("Expected regions %+v, got %+v", expectedRegions, result.ProtectedRegions)
+	}
+	if unused := result.UnusedDirectives(); len(unused) != 1 || unused[0].DirectiveLine != 7 {
+		t.Errorf("Expected only the trailing keep on line 7 to be unused, got %+v", unused)
+	}
+}
+
+func TestSupportedLanguagesReturnsCopies(t *testing.T) {
+	languages := SupportedLanguages()
+	delete(languages, "go")
+	languages["typescript"].Extensions[0] = ".changed"
+
+	lang, ok := LanguageByKey("go")
+	if !ok || lang.Name != supportedLanguages["go"].Name {
+		t.Fatalf("Expected go to remain supported, got %+v", lang)
+	}
+	lang.Pragmas[0] = "changed"
+	if supportedLanguages["go"].Pragmas[0] == "changed" {
+		t.Error("Changing a language from LanguageByKey modified the built-in language")
+	}
+	if _, ok := GetLanguageByExtension("a.ts"); !ok {
+		t.Error("Changing a language from SupportedLanguages modified extension detection")
+	}
+}
@@ -0,0 +1,395 @@
+package remover
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type Language struct {
+	Name                        string
+	Extensions                  []string
+	SingleLineStart             string
+	MultiLineStart              string
+	MultiLineEnd                string
+	AdditionalMultiLinePatterns []MultiLinePattern
+	StringDelimiters            []StringDelimiter
+	DocComments                 []DocCommentRule
+	Directives                  []string
+	DirectiveAnchors            []string
+	Pragmas                     []string
+	PragmasAtStart              bool
+	Docstrings                  bool
+	NestedComments              bool
+	PreserveDocComments         bool
+	CommentsAtWordStart         bool
+	Heredocs                    bool
+	LineContinuation            bool
+	Preprocessor                bool
+}
+
+// MultiLinePattern wraps a block comment in extra delimiters, like JSX's
+// {/* ... */}. The lexer sees an ordinary block comment; the wrapper is only
+// removed along with it where it cannot be code.
+type MultiLinePattern struct {
+	Start string
+	End   string
+}
+
+// StringDelimiter describes one form of string literal. A single-character
+// Interpolation that is doubled, as in Python's "{{", is literal text. A Char
+// delimiter only opens a literal holding a single character, which tells
+// Rust's '"' apart from the lifetime 'a. A Delimited literal carries its own
+// terminator between Start and "(", as in C++'s R"tag(...)tag".
+// Substitutions are further openers of embedded code, like the shell's $(
+// and backticks; they close like Interpolation.
+type StringDelimiter struct {
+	Start         string
+	End           string
+	Escape        byte
+	MultiLine     bool
+	Interpolation string
+	Char          bool
+	Delimited     bool
+	Substitutions []string
+}
+
+// DocCommentRule marks comments starting with Marker as documentation when
+// the next line is a declaration matching Declaration. Inner doc comments,
+// such as Rust's //!, document the enclosing item and need no declaration.
+type DocCommentRule struct {
+	Marker      string
+	Declaration *regexp.Regexp
+	Inner       bool
+}
+
+var defaultStringDelimiters = []StringDelimiter{
+	{Start: `"`, End: `"`, Escape: '\\'},
+	{Start: "'", End: "'", Escape: '\\'},
+	{Start: "`", End: "`", Escape: '\\'},
+}
+
+// pythonStringDelimiters lists every string prefix (r, u, b, f and their
+// combinations, in any case) with triple and single quotes, longest first.
+func pythonStringDelimiters() []StringDelimiter {
+	prefixes := []string{""}
+	for _, prefix := range []string{"r", "u", "b", "f", "rb", "br", "fr", "rf"} {
+		variants := []string{""}
+		for _, c := range prefix {
+			var next []string
+			for _, v := range variants {
+				next = append(next, v+string(c), v+strings.ToUpper(string(c)))
+			}
+			variants = next
+		}
+		prefixes = append(prefixes, variants...)
+	}
+
+	var delimiters []StringDelimiter
+	for _, quote := range []string{`"""`, "'''", `"`, "'"} {
+		for _, prefix := range prefixes {
+			delim := StringDelimiter{Start: prefix + quote, End: quote, Escape: '\\', MultiLine: len(quote) == 3}
+			if strings.ContainsAny(prefix, "fF") {
+				delim.Interpolation = "{"
+			}
+			delimiters = append(delimiters, delim)
+		}
+	}
+	slices.SortStableFunc(delimiters, func(a, b StringDelimiter) int { return len(b.Start) - len(a.Start) })
+	return delimiters
+}
+
+// maxRawStringHashes bounds the r#"..."# forms generated for Rust; raw
+// strings with more hashes are not seen in practice.
+const maxRawStringHashes = 8
+
+// rustStringDelimiters lists raw strings with up to maxRawStringHashes
+// hashes, most first, followed by ordinary and character literals. Byte and
+// C string prefixes (b"", br"", c"") lex as code followed by these.
+func rustStringDelimiters() []StringDelimiter {
+	var delimiters []StringDelimiter
+	for n := maxRawStringHashes; n >= 0; n-- {
+		hashes := strings.Repeat("#", n)
+		delimiters = append(delimiters, StringDelimiter{Start: "r" + hashes + `"`, End: `"` + hashes, MultiLine: true})
+	}
+	return append(delimiters,
+		StringDelimiter{Start: `"`, End: `"`, Escape: '\\', MultiLine: true},
+		StringDelimiter{Start: "'", End: "'", Escape: '\\', Char: true},
+	)
+}
+
+var supportedLanguages = map[string]Language{
+	"typescript": {
+		Name:            "TypeScript/JavaScript",
+		Extensions:      []string{".ts", ".tsx", ".js", ".jsx"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		AdditionalMultiLinePatterns: []MultiLinePattern{
+			{Start: "{/*", End: "*/}"},
+		},
+		StringDelimiters: []StringDelimiter{
+			{Start: "`", End: "`", Escape: '\\', MultiLine: true, Interpolation: "${"},
+			{Start: `"`, End: `"`, Escape: '\\'},
+			{Start: "'", End: "'", Escape: '\\'},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "/**"},
+		},
+		Pragmas: []string{
+			"eslint-disable", "eslint-enable", "eslint-env", "prettier-ignore",
+			"@ts-ignore", "@ts-expect-error", "@ts-nocheck", "@ts-check",
+			"istanbul ignore", "c8 ignore", "v8 ignore", "biome-ignore",
+			"<reference ", "<amd-module", "# sourceMappingURL=", "@license", "@preserve",
+			"webpackChunkName", "@vite-ignore",
+		},
+	},
+	"go": {
+		Name:            "Go",
+		Extensions:      []string{".go"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: "`", End: "`", MultiLine: true},
+			{Start: `"`, End: `"`, Escape: '\\'},
+			{Start: "'", End: "'", Escape: '\\'},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "//", Declaration: regexp.MustCompile(`^(func\s+(\([^)]*\)\s*)?[A-Z]|(type|var|const)\s+[A-Z]|(type|var|const)\s*\(|package\s|[A-Z]\w*(\s+\S|,))`)},
+		},
+		Directives:       []string{"//go:", "// +build", "//nolint", "//lint:", "//line ", "//export ", "//extern "},
+		DirectiveAnchors: []string{`import "C"`},
+		Pragmas:          []string{"#nosec", "NOSONAR"},
+	},
+	"sql": {
+		Name:            "SQL",
+		Extensions:      []string{".sql"},
+		SingleLineStart: "--",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: "$$", End: "$$", MultiLine: true},
+			{Start: "'", End: "'", MultiLine: true},
+			{Start: `"`, End: `"`, MultiLine: true},
+		},
+		Pragmas: []string{"noqa", "sqlfluff:"},
+	},
+	"json": {
+		Name:            "JSON",
+		Extensions:      []string{".json"},
+		SingleLineStart: "//",
+		MultiLineStart:  "",
+		MultiLineEnd:    "",
+		StringDelimiters: []StringDelimiter{
+			{Start: `"`, End: `"`, Escape: '\\'},
+		},
+	},
+	"php": {
+		Name:            "PHP",
+		Extensions:      []string{".php", ".phtml"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: `"`, End: `"`, Escape: '\\', MultiLine: true},
+			{Start: "'", End: "'", Escape: '\\', MultiLine: true},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "/**"},
+		},
+		Pragmas: []string{
+			"@phpstan-ignore", "@psalm-suppress", "phpcs:", "@codeCoverageIgnore",
+			"@noinspection", "@phpcsSuppress",
+		},
+	},
+	"csharp": {
+		Name:            "C#",
+		Extensions:      []string{".cs"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: `"""`, End: `"""`, MultiLine: true},
+			{Start: `@$"`, End: `"`, MultiLine: true},
+			{Start: `@"`, End: `"`, MultiLine: true},
+			{Start: `"`, End: `"`, Escape: '\\'},
+			{Start: "'", End: "'", Escape: '\\'},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "///"},
+			{Marker: "/**"},
+		},
+		Pragmas: []string{"ReSharper disable", "ReSharper restore", "<auto-generated", "dotcover disable", "dotcover enable"},
+	},
+	"python": {
+		Name:             "Python",
+		Extensions:       []string{".py", ".pyw", ".pyi"},
+		SingleLineStart:  "#",
+		StringDelimiters: pythonStringDelimiters(),
+		Directives:       []string{"#!"},
+		Pragmas: []string{
+			"type:", "noqa", "coding:", "coding=", "-*-", "vim:", "pylint:", "pragma: no cover", "pragma: no branch",
+			"mypy:", "pyright:", "ruff:", "isort:", "fmt: off", "fmt: on", "fmt: skip", "nosec",
+		},
+		PragmasAtStart: true,
+		Docstrings:     true,
+	},
+	"rust": {
+		Name:             "Rust",
+		Extensions:       []string{".rs"},
+		SingleLineStart:  "//",
+		MultiLineStart:   "/*",
+		MultiLineEnd:     "*/",
+		StringDelimiters: rustStringDelimiters(),
+		DocComments: []DocCommentRule{
+			{Marker: "///"},
+			{Marker: "/**"},
+			{Marker: "//!", Inner: true},
+			{Marker: "/*!", Inner: true},
+		},
+		Pragmas:             []string{"SAFETY:", "@generated"},
+		NestedComments:      true,
+		PreserveDocComments: true,
+	},
+	"shell": {
+		Name:            "Shell",
+		Extensions:      []string{".sh", ".bash", ".zsh"},
+		SingleLineStart: "#",
+		StringDelimiters: []StringDelimiter{
+			{Start: "$'", End: "'", Escape: '\\', MultiLine: true},
+			{Start: "'", End: "'", MultiLine: true},
+			{Start: `"`, End: `"`, Escape: '\\', MultiLine: true, Interpolation: "${", Substitutions: []string{"$(", "`"}},
+			{Start: "`", End: "`", Escape: '\\', MultiLine: true},
+		},
+		Directives:          []string{"#!"},
+		Pragmas:             []string{"shellcheck "},
+		CommentsAtWordStart: true,
+		Heredocs:            true,
+	},
+	"cpp": {
+		Name:            "C/C++",
+		Extensions:      []string{".c", ".h", ".cpp", ".hpp", ".cc", ".cxx"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: `R"`, End: `"`, MultiLine: true, Delimited: true},
+			{Start: `"`, End: `"`, Escape: '\\'},
+			{Start: "'", End: "'", Escape: '\\', Char: true},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "///"},
+			{Marker: "//!"},
+			{Marker: "/**"},
+			{Marker: "/*!"},
+		},
+		Pragmas: []string{
+			"NOLINT", "clang-format off", "clang-format on", "IWYU pragma:", "cppcheck-suppress",
+			"LCOV_EXCL", "NOSONAR", "fallthrough", "FALLTHROUGH", "falls through",
+		},
+		LineContinuation: true,
+		Preprocessor:     true,
+	},
+	"java": {
+		Name:            "Java",
+		Extensions:      []string{".java"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: `"""`, End: `"""`, Escape: '\\', MultiLine: true},
+			{Start: `"`, End: `"`, Escape: '\\'},
+			{Start: "'", End: "'", Escape: '\\'},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "/**"},
+		},
+		Pragmas: []string{
+			"noinspection", "@formatter:off", "@formatter:on", "CHECKSTYLE:OFF", "CHECKSTYLE:ON",
+			"NOPMD", "NOSONAR", "$NON-NLS-", "fall through", "falls through",
+		},
+	},
+	"kotlin": {
+		Name:            "Kotlin",
+		Extensions:      []string{".kt", ".kts"},
+		SingleLineStart: "//",
+		MultiLineStart:  "/*",
+		MultiLineEnd:    "*/",
+		StringDelimiters: []StringDelimiter{
+			{Start: `"""`, End: `"""`, MultiLine: true, Interpolation: "${"},
+			{Start: `"`, End: `"`, Escape: '\\', Interpolation: "${"},
+			{Start: "'", End: "'", Escape: '\\'},
+		},
+		DocComments: []DocCommentRule{
+			{Marker: "/**"},
+		},
+		Pragmas: []string{
+			"noinspection", "@formatter:off", "@formatter:on", "ktlint-disable", "ktlint-enable", "NOSONAR",
+		},
+		NestedComments: true,
+	},
+}
+
+// SupportedLanguages returns the built-in languages by key. The map and the
+// languages in it are copies, so changing them does not affect detection.
+func SupportedLanguages() map[string]Language {
+	languages := make(map[string]Language, len(supportedLanguages))
+	for key, lang := range supportedLanguages {
+		languages[key] = lang.clone()
+	}
+	return languages
+}
+
+// LanguageByKey returns a copy of the built-in language with key.
+func LanguageByKey(key string) (Language, bool) {
+	lang, ok := supportedLanguages[key]
+	if !ok {
+		return Language{}, false
+	}
+	return lang.clone(), true
+}
+
+func (l Language) clone() Language {
+	l.Extensions = slices.Clone(l.Extensions)
+	l.AdditionalMultiLinePatterns = slices.Clone(l.AdditionalMultiLinePatterns)
+	l.StringDelimiters = slices.Clone(l.StringDelimiters)
+	for i := range l.StringDelimiters {
+		l.StringDelimiters[i].Substitutions = slices.Clone(l.StringDelimiters[i].Substitutions)
+	}
+	l.DocComments = slices.Clone(l.DocComments)
+	l.Directives = slices.Clone(l.Directives)
+	l.DirectiveAnchors = slices.Clone(l.DirectiveAnchors)
+	l.Pragmas = slices.Clone(l.Pragmas)
+	return l
+}
+
+func GetLanguageByExtension(filename string) (*Language, bool) {
+	ext := strings.ToLower(filename)
+	dotIndex := strings.LastIndex(ext, ".")
+	if dotIndex == -1 {
+		return nil, false
+	}
+
+	extension := ext[dotIndex:]
+
+	for _, lang := range supportedLanguages {
+		if slices.Contains(lang.Extensions, extension) {
+			lang = lang.clone()
+			return &lang, true
+		}
+	}
+
+	return nil, false
+}
+
+// LanguageKey returns the key of lang among the supported languages, which is also
+// the scope used by ignore patterns and the pragmas config.
+func LanguageKey(lang Language) string {
+	for key, supported := range supportedLanguages {
+		if supported.Name == lang.Name {
+			return key
+		}
+	}
+	return ""
+}
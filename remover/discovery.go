@@ -0,0 +1,204 @@
+package remover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+type FileInfo struct {
+	Path     string
+	Language Language
+}
+
+func DiscoverGlobFiles(pattern string) ([]FileInfo, error) {
+	var files []FileInfo
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern '%s': %v", pattern, err)
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match pattern: %s", pattern)
+	}
+
+	for _, match := range matches {
+		if stat, err := os.Stat(match); err == nil && stat.IsDir() {
+			continue
+		}
+
+		lang, supported := GetLanguageByExtension(match)
+		if supported {
+			files = append(files, FileInfo{
+				Path:     match,
+				Language: *lang,
+			})
+		}
+	}
+
+	return files, nil
+}
+
+func DiscoverFiles(inputPath string, recursive bool, excludePatterns []string) ([]FileInfo, error) {
+	var files []FileInfo
+
+	if strings.Contains(inputPath, "*") || strings.Contains(inputPath, "?") || strings.Contains(inputPath, "[") {
+		return DiscoverGlobFiles(inputPath)
+	}
+
+	stat, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("path does not exist: %s", inputPath)
+	}
+
+	var ignorePatterns []string
+	var dirToCheck string
+	if stat.IsDir() {
+		dirToCheck = inputPath
+	} else {
+		dirToCheck = filepath.Dir(inputPath)
+	}
+
+	gitignorePath := filepath.Join(dirToCheck, ".gitignore")
+	if data, err := os.ReadFile(gitignorePath); err == nil {
+		lines := strings.Split(string(data), "\n")
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				ignorePatterns = append(ignorePatterns, trimmed)
+			}
+		}
+	}
+
+	commenterignorePath := filepath.Join(dirToCheck, ".commenterignore")
+	if data, err := os.ReadFile(commenterignorePath); err == nil {
+		lines := strings.Split(string(data), "\n")
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				ignorePatterns = append(ignorePatterns, trimmed)
+			}
+		}
+	}
+
+	var ign *ignore.GitIgnore
+	if len(ignorePatterns) > 0 {
+		ign = ignore.CompileIgnoreLines(ignorePatterns...)
+	}
+
+	if stat.IsDir() {
+		err = processDirectory(inputPath, recursive, &files, ign, excludePatterns)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lang, supported := GetLanguageByExtension(inputPath)
+		if !supported {
+			return nil, fmt.Errorf("unsupported file type: %s", filepath.Ext(inputPath))
+		}
+		if ign == nil || !ign.MatchesPath(filepath.Base(inputPath)) {
+			if !matchesExcludePatterns(inputPath, excludePatterns) {
+				files = append(files, FileInfo{
+					Path:     inputPath,
+					Language: *lang,
+				})
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func matchesExcludePatterns(filePath string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	fileName := filepath.Base(filePath)
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, fileName); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func processDirectory(dirPath string, recursive bool, files *[]FileInfo, ign *ignore.GitIgnore, excludePatterns []string) error {
+	if recursive {
+		return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, _ := filepath.Rel(dirPath, path)
+			if ign != nil && ign.MatchesPath(relPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if matchesExcludePatterns(path, excludePatterns) {
+				return nil
+			}
+			lang, supported := GetLanguageByExtension(path)
+			if supported {
+				*files = append(*files, FileInfo{
+					Path:     path,
+					Language: *lang,
+				})
+			}
+			return nil
+		})
+	} else {
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			fullPath := filepath.Join(dirPath, entry.Name())
+			relPath, _ := filepath.Rel(dirPath, fullPath)
+			if ign != nil && ign.MatchesPath(relPath) {
+				continue
+			}
+			if entry.IsDir() {
+				continue
+			}
+			if matchesExcludePatterns(fullPath, excludePatterns) {
+				continue
+			}
+			lang, supported := GetLanguageByExtension(fullPath)
+			if supported {
+				*files = append(*files, FileInfo{
+					Path:     fullPath,
+					Language: *lang,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func FilterFilesByExtensions(files []FileInfo, extensions []string) []FileInfo {
+	if len(extensions) == 0 {
+		return files
+	}
+
+	var filtered []FileInfo
+	for _, file := range files {
+		fileExt := strings.ToLower(filepath.Ext(file.Path))
+		for _, ext := range extensions {
+			if strings.ToLower(ext) == fileExt {
+				filtered = append(filtered, file)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
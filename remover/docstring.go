@@ -0,0 +1,173 @@
+package remover
+
+import (
+	"regexp"
+	"strings"
+)
+
+var docstringOwner = regexp.MustCompile(`^(async\s+def|def|class)\s`)
+
+// collectDocstrings finds string statements that open a module, class or
+// function body. A docstring that is the only statement of its body is left
+// alone, since removing it would leave the body empty.
+func collectDocstrings(lines []string, lineTokens [][]lexToken) []blockComment {
+	var docstrings []blockComment
+	expect, moduleLevel := true, true
+	header, depth := false, 0
+	headerIndent, ownerIndent := 0, 0
+
+	for i := 0; i < len(lines); i++ {
+		line, tokens := lines[i], lineTokens[i]
+		first, ok := firstSignificantToken(line, tokens)
+		if !ok || first.Kind == tokenLineComment || first.Continued {
+			continue
+		}
+
+		if expect && first.Kind == tokenString && isDocstringLiteral(line[first.Start:first.End]) {
+			if doc, ok := docstringAt(lines, lineTokens, i, first); ok {
+				if moduleLevel || hasFollowingStatement(lines, lineTokens, doc.endLine, ownerIndent) {
+					docstrings = append(docstrings, doc)
+				}
+				expect, moduleLevel = false, false
+				i = doc.endLine
+				continue
+			}
+		}
+
+		moduleLevel, expect = false, false
+		if !header && docstringOwner.MatchString(strings.TrimSpace(line)) {
+			header, depth, headerIndent = true, 0, indentation(line)
+		}
+		if !header {
+			continue
+		}
+		code := codeText(line, tokens)
+		depth += bracketDepth(code)
+		if depth <= 0 {
+			header = false
+			if strings.HasSuffix(strings.TrimSpace(code), ":") {
+				expect, ownerIndent = true, headerIndent
+			}
+		}
+	}
+
+	return docstrings
+}
+
+func firstSignificantToken(line string, tokens []lexToken) (lexToken, bool) {
+	for _, token := range tokens {
+		if token.Kind == tokenCode && strings.TrimSpace(line[token.Start:token.End]) == "" {
+			continue
+		}
+		return token, true
+	}
+	return lexToken{}, false
+}
+
+// isDocstringLiteral rejects f-strings and bytes, which Python never treats
+// as docstrings.
+func isDocstringLiteral(text string) bool {
+	prefix := text[:strings.IndexAny(text, `"'`)]
+	return !strings.ContainsAny(prefix, "fFbB")
+}
+
+// docstringAt follows the string starting with first on line start to its
+// end and accepts it if nothing but a comment follows it on that line.
+func docstringAt(lines []string, lineTokens [][]lexToken, start int, first lexToken) (blockComment, bool) {
+	doc := blockComment{startLine: start, startCol: first.Start, kind: CommentDocstring}
+	end, token := start, first
+	for token.Open {
+		end++
+		if end >= len(lines) || len(lineTokens[end]) == 0 {
+			return blockComment{}, false
+		}
+		token = lineTokens[end][0]
+	}
+
+	for _, next := range lineTokens[end] {
+		if next.Start < token.End {
+			continue
+		}
+		if next.Kind == tokenLineComment {
+			break
+		}
+		if next.Kind != tokenCode || strings.TrimSpace(lines[end][next.Start:next.End]) != "" {
+			return blockComment{}, false
+		}
+	}
+
+	doc.endLine, doc.endCol = end, token.End
+	var text strings.Builder
+	for l := start; l <= end; l++ {
+		from, to := 0, len(lines[l])
+		if l == start {
+			from = doc.startCol
+		}
+		if l == end {
+			to = doc.endCol
+		}
+		if l > start {
+			text.WriteByte('\n')
+		}
+		text.WriteString(lines[l][from:to])
+	}
+	doc.text = text.String()
+	return doc, true
+}
+
+func hasFollowingStatement(lines []string, lineTokens [][]lexToken, after, ownerIndent int) bool {
+	for l := after + 1; l < len(lines); l++ {
+		first, ok := firstSignificantToken(lines[l], lineTokens[l])
+		if !ok || first.Kind == tokenLineComment {
+			continue
+		}
+		return indentation(lines[l]) > ownerIndent
+	}
+	return false
+}
+
+// codeText returns the code of a line with strings and comments blanked
+// out, so that quotes and brackets inside them do not count.
+func codeText(line string, tokens []lexToken) string {
+	var code strings.Builder
+	for _, token := range tokens {
+		if token.Kind == tokenCode {
+			code.WriteString(line[token.Start:token.End])
+		} else if token.Kind == tokenString {
+			code.WriteString(`""`)
+		}
+	}
+	return code.String()
+}
+
+func bracketDepth(code string) int {
+	depth := 0
+	for _, c := range code {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// docstringBody strips the prefix and quotes of a docstring literal.
+func docstringBody(text string) (string, bool) {
+	quote := strings.IndexAny(text, `"'`)
+	if quote < 0 || strings.Trim(text[:quote], "rRuU") != "" {
+		return "", false
+	}
+	text = text[quote:]
+	for _, q := range []string{`"""`, "'''", `"`, "'"} {
+		if len(text) >= 2*len(q) && strings.HasPrefix(text, q) && strings.HasSuffix(text, q) {
+			return strings.TrimSpace(text[len(q) : len(text)-len(q)]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,223 @@
+package remover
+
+import (
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Position locates a byte offset in the file. Offset counts from the first
+// byte of the file, including a BOM; Line and the columns are 1-based, with
+// columns measured in bytes, runes and UTF-16 code units from the line start.
+type Position struct {
+	Offset      int `json:"offset"`
+	Line        int `json:"line"`
+	Column      int `json:"column"`
+	RuneColumn  int `json:"runeColumn"`
+	UTF16Column int `json:"utf16Column"`
+}
+
+// Edit replaces the bytes from Start up to (not including) End with
+// Replacement. Comment is the index of the RemovedComment it belongs to.
+type Edit struct {
+	Start       Position    `json:"start"`
+	End         Position    `json:"end"`
+	Replacement string      `json:"replacement"`
+	Kind        CommentKind `json:"kind"`
+	Comment     int         `json:"comment"`
+}
+
+type sourceIndex struct {
+	lines   []string
+	endings []string
+	starts  []int
+}
+
+func newSourceIndex(r *Result) sourceIndex {
+	idx := sourceIndex{lines: r.SourceLines, endings: r.SourceEndings, starts: make([]int, len(r.SourceLines)+1)}
+	offset := 0
+	if r.BOM {
+		offset = len(utf8BOM)
+	}
+	for i, line := range r.SourceLines {
+		idx.starts[i] = offset
+		offset += len(line) + len(r.SourceEndings[i])
+	}
+	idx.starts[len(r.SourceLines)] = offset
+	return idx
+}
+
+func (idx sourceIndex) position(offset int) Position {
+	i := max(sort.SearchInts(idx.starts, offset+1)-1, 0)
+	if i == len(idx.lines) && i > 0 && idx.endings[i-1] == "" {
+		i--
+	}
+	if i == len(idx.lines) {
+		return Position{Offset: offset, Line: i + 1, Column: 1, RuneColumn: 1, UTF16Column: 1}
+	}
+
+	col := offset - idx.starts[i]
+	line := idx.lines[i]
+	extra := max(col-len(line), 0)
+	prefix := line[:col-extra]
+	return Position{
+		Offset:      offset,
+		Line:        i + 1,
+		Column:      col + 1,
+		RuneColumn:  utf8.RuneCountInString(prefix) + extra + 1,
+		UTF16Column: utf16Len(prefix) + extra + 1,
+	}
+}
+
+func utf16Len(s string) int {
+	units := 0
+	for _, r := range s {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// buildEdits turns the per-line outcome of processing into byte-range edits,
+// each attributed to one removed comment, so applying them all reproduces the
+// processed file.
+func buildEdits(r *Result, lineEdits []lineEdit) []Edit {
+	idx := newSourceIndex(r)
+	ranges := commentRanges(r, idx)
+
+	type span struct {
+		start, end  int
+		replacement string
+		comment     int
+	}
+	var spans []span
+	first := 0
+	for i, edit := range lineEdits {
+		line := r.SourceLines[i]
+		s := span{comment: -1}
+		switch {
+		case edit.remove:
+			s.start, s.end = idx.starts[i], idx.starts[i+1]
+		case edit.text != line:
+			p, q := commonAffixes(line, edit.text)
+			s.start, s.end = idx.starts[i]+p, idx.starts[i]+len(line)-q
+			s.replacement = edit.text[p : len(edit.text)-q]
+		default:
+			continue
+		}
+		for first < len(r.RemovedComments) && r.RemovedComments[first].EndLineNumber < i+1 {
+			first++
+		}
+		s.comment = owningComment(r.RemovedComments, ranges, first, i+1, s.start, s.end)
+
+		if n := len(spans); n > 0 && spans[n-1].end == s.start && spans[n-1].comment == s.comment {
+			spans[n-1].end = s.end
+			spans[n-1].replacement += s.replacement
+			continue
+		}
+		spans = append(spans, s)
+	}
+
+	if n := len(lineEdits); n > 0 && !r.TrailingNewline && lineEdits[n-1].remove {
+		kept := n - 1
+		for kept >= 0 && lineEdits[kept].remove {
+			kept--
+		}
+		if kept >= 0 {
+			for j := range spans {
+				if spans[j].start == idx.starts[kept+1] {
+					spans[j].start = idx.starts[kept] + len(r.SourceLines[kept])
+					if j > 0 && spans[j-1].end > spans[j].start {
+						spans[j].start = spans[j-1].end
+					}
+					break
+				}
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, len(spans))
+	for _, s := range spans {
+		edit := Edit{
+			Start:       idx.position(s.start),
+			End:         idx.position(s.end),
+			Replacement: s.replacement,
+			Comment:     s.comment,
+		}
+		if s.comment >= 0 {
+			edit.Kind = r.RemovedComments[s.comment].Kind
+		}
+		edits = append(edits, edit)
+	}
+	return edits
+}
+
+func commentRanges(r *Result, idx sourceIndex) [][2]int {
+	ranges := make([][2]int, len(r.RemovedComments))
+	for i, c := range r.RemovedComments {
+		ranges[i] = [2]int{idx.starts[c.LineNumber-1] + c.Column - 1, idx.starts[c.EndLineNumber-1] + c.EndColumn - 1}
+	}
+	return ranges
+}
+
+// owningComment prefers a comment whose bytes overlap the edit and falls back
+// to one that spans the line, e.g. for whitespace left around a comment.
+// Comments are ordered by start line, and none before first reach line.
+func owningComment(comments []RemovedComment, ranges [][2]int, first, line, start, end int) int {
+	fallback := -1
+	for i := first; i < len(comments) && comments[i].LineNumber <= line; i++ {
+		if line > comments[i].EndLineNumber {
+			continue
+		}
+		if ranges[i][0] < end && start < ranges[i][1] {
+			return i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+func commonAffixes(a, b string) (int, int) {
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	for p > 0 && p < len(a) && !utf8.RuneStart(a[p]) {
+		p--
+	}
+
+	q := 0
+	for q < len(a)-p && q < len(b)-p && a[len(a)-1-q] == b[len(b)-1-q] {
+		q++
+	}
+	for q > 0 && !utf8.RuneStart(a[len(a)-q]) {
+		q--
+	}
+	return p, q
+}
+
+// applyLineEdits derives ModifiedLines, ModifiedEndings and LineMap from the
+// same per-line outcome buildEdits turns into byte ranges, so Content() is
+// exactly the source with Edits applied.
+func (r *Result) applyLineEdits(lineEdits []lineEdit) {
+	r.LineMap = make([]int, len(lineEdits))
+	r.ModifiedLines = make([]string, 0, len(lineEdits))
+	r.ModifiedEndings = make([]string, 0, len(lineEdits))
+	for i, edit := range lineEdits {
+		if edit.remove {
+			r.LineMap[i] = -1
+			continue
+		}
+		r.LineMap[i] = len(r.ModifiedLines)
+		r.ModifiedLines = append(r.ModifiedLines, edit.text)
+		r.ModifiedEndings = append(r.ModifiedEndings, r.SourceEndings[i])
+	}
+
+	// Like the edits, removing the last lines of a file without a trailing
+	// newline takes the newline before them along.
+	if n := len(r.ModifiedLines); n > 0 && !r.TrailingNewline && lineEdits[len(lineEdits)-1].remove {
+		r.ModifiedEndings[n-1] = ""
+	}
+	r.RemainingLines = len(r.ModifiedLines)
+}
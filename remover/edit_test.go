@@ -0,0 +1,78 @@
+package remover
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditsMatchContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		options  Options
+		input    string
+		expected string
+		lineMap  []int
+	}{
+		{
+			name:     "removed line merged with a partial edit of the next",
+			lang:     "cpp",
+			options:  Options{RemoveBlocks: true},
+			input:    "x; /* a\n b\n c */ y; // z\nw;\n",
+			expected: "x;\ny;\nw;\n",
+			lineMap:  []int{0, -1, 1, 2},
+		},
+		{
+			name:     "only a BOM",
+			lang:     "typescript",
+			input:    utf8BOM,
+			expected: utf8BOM,
+			lineMap:  []int{0},
+		},
+		{
+			name:     "BOM before a removed comment",
+			lang:     "typescript",
+			input:    utf8BOM + "// c\nx\n",
+			expected: utf8BOM + "x\n",
+			lineMap:  []int{-1, 0},
+		},
+		{
+			name:     "last line removed after a blank line without a trailing newline",
+			lang:     "typescript",
+			input:    "a\n\n// c",
+			expected: "a\n",
+			lineMap:  []int{0, 1, -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Remove([]byte(tt.input), supportedLanguages[tt.lang], tt.options)
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			if content := string(result.Content()); content != tt.expected {
+				t.Errorf("Expected content %q, got %q", tt.expected, content)
+			}
+			if !reflect.DeepEqual(result.LineMap, tt.lineMap) {
+				t.Errorf("Expected line map %v, got %v", tt.lineMap, result.LineMap)
+			}
+			for i, target := range result.LineMap {
+				if target >= len(result.ModifiedLines) {
+					t.Errorf("Line %d maps past the %d modified lines", i+1, len(result.ModifiedLines))
+				}
+			}
+
+			original := string(result.Original())
+			applied, last := "", 0
+			for _, edit := range result.Edits {
+				applied += original[last:edit.Start.Offset] + edit.Replacement
+				last = edit.End.Offset
+			}
+			applied += original[last:]
+			if applied != string(result.Content()) {
+				t.Errorf("Applying the edits gives %q, Content() gives %q", applied, result.Content())
+			}
+		})
+	}
+}
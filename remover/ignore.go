@@ -0,0 +1,115 @@
+package remover
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	regexPatternPrefix  = "re:"
+	languageScopePrefix = "lang="
+)
+
+type IgnorePattern struct {
+	Language string
+	Text     string
+	Regexp   *regexp.Regexp
+}
+
+func CompileIgnorePatterns(patterns []string) ([]IgnorePattern, error) {
+	compiled := make([]IgnorePattern, 0, len(patterns))
+	for _, raw := range patterns {
+		if raw == "" {
+			continue
+		}
+
+		var pattern IgnorePattern
+		text := raw
+		if scoped, ok := strings.CutPrefix(text, languageScopePrefix); ok {
+			key, rest, found := strings.Cut(scoped, ":")
+			if _, known := supportedLanguages[key]; !found || !known {
+				return nil, fmt.Errorf("invalid ignore pattern %q: unknown language %q", raw, key)
+			}
+			pattern.Language = key
+			text = rest
+		}
+
+		if expr, ok := strings.CutPrefix(text, regexPatternPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignore pattern %q: %v", raw, err)
+			}
+			pattern.Regexp = re
+		} else {
+			pattern.Text = text
+		}
+
+		compiled = append(compiled, pattern)
+	}
+	return compiled, nil
+}
+
+func (p IgnorePattern) Matches(body string, langKey string) bool {
+	if p.Language != "" && p.Language != langKey {
+		return false
+	}
+	if p.Regexp != nil {
+		return p.Regexp.MatchString(body)
+	}
+	return strings.Contains(body, p.Text)
+}
+
+func shouldIgnoreComment(comment string, lang Language, ignorePatterns []IgnorePattern) bool {
+	body := extractCommentBody(comment, lang)
+	langKey := LanguageKey(lang)
+
+	for _, pattern := range ignorePatterns {
+		if pattern.Matches(body, langKey) {
+			return true
+		}
+	}
+
+	return hasPragma(body, lang)
+}
+
+// isPragmaComment reports whether comment is kept for a pragma in the
+// language's catalog.
+func isPragmaComment(comment string, lang Language) bool {
+	return hasPragma(extractCommentBody(comment, lang), lang)
+}
+
+func hasPragma(body string, lang Language) bool {
+	for _, pragma := range lang.Pragmas {
+		if matchesPragma(body, pragma, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPragma reports whether body holds pragma. For PragmasAtStart
+// languages the pragma has to open the body or one of the comments chained
+// after it, as in "# type: ignore  # noqa", so prose that merely mentions it
+// is not kept.
+func matchesPragma(body, pragma string, lang Language) bool {
+	if !lang.PragmasAtStart {
+		return strings.Contains(body, pragma)
+	}
+	for part := range strings.SplitSeq(body, lang.SingleLineStart) {
+		if strings.HasPrefix(strings.TrimSpace(part), pragma) {
+			return true
+		}
+	}
+	return false
+}
+
+func extractCommentBody(comment string, lang Language) string {
+	comment = strings.TrimSpace(comment)
+	for _, token := range newLexer(lang).scanLine(comment) {
+		if token.Kind == tokenLineComment || token.Kind == tokenBlockComment {
+			return CommentBody(comment[token.Start:token.End], lang)
+		}
+	}
+	return CommentBody(comment, lang)
+}
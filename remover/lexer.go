@@ -0,0 +1,388 @@
+package remover
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenCode tokenKind = iota
+	tokenString
+	tokenLineComment
+	tokenBlockComment
+)
+
+// lexToken is a span of a single line. Continued marks a token that began
+// on an earlier line, Open one that carries on past the end of the line.
+type lexToken struct {
+	Kind      tokenKind
+	Start     int
+	End       int
+	Continued bool
+	Open      bool
+}
+
+type lexFrame struct {
+	delim  StringDelimiter
+	interp bool
+	braces int
+	open   byte
+	close  byte
+}
+
+// enterCode switches the frame to the embedded code opened by opener. The
+// code ends at the matching bracket, or at the next backtick for "`".
+func (f *lexFrame) enterCode(opener string) {
+	f.interp, f.braces = true, 0
+	switch last := opener[len(opener)-1]; last {
+	case '{':
+		f.open, f.close = '{', '}'
+	case '(':
+		f.open, f.close = '(', ')'
+	default:
+		f.open, f.close = 0, last
+	}
+}
+
+// heredoc is a shell here-document whose body starts on the next line and
+// runs up to a line holding only word.
+type heredoc struct {
+	word      string
+	stripTabs bool
+}
+
+type lexer struct {
+	lang       Language
+	delimiters []StringDelimiter
+	openers    [256]bool
+	inBlock    bool
+	depth      int
+	frames     []lexFrame
+	heredocs   []heredoc
+	arithmetic int
+	continued  bool
+}
+
+func newLexer(lang Language) *lexer {
+	delimiters := lang.StringDelimiters
+	if delimiters == nil {
+		delimiters = defaultStringDelimiters
+	}
+	l := &lexer{lang: lang, delimiters: delimiters}
+	for _, delim := range delimiters {
+		l.openers[delim.Start[0]] = true
+	}
+	return l
+}
+
+// blockDepth returns how many block comments are open. It is at most one
+// unless the language nests block comments.
+func (l *lexer) blockDepth() int {
+	return l.depth
+}
+
+func (l *lexer) enterBlockComment() {
+	l.inBlock = true
+	l.depth = 1
+}
+
+func (l *lexer) leaveBlockComment() {
+	l.inBlock = false
+	l.depth = 0
+}
+
+func (l *lexer) kind() tokenKind {
+	switch {
+	case l.inBlock:
+		return tokenBlockComment
+	case len(l.frames) > 0:
+		return tokenString
+	default:
+		return tokenCode
+	}
+}
+
+func (l *lexer) scanLine(line string) []lexToken {
+	return l.appendLine(make([]lexToken, 0, 4), line)
+}
+
+func (l *lexer) appendLine(tokens []lexToken, line string) []lexToken {
+	if len(l.heredocs) > 0 {
+		return l.appendHeredocLine(tokens, line)
+	}
+	if l.continued {
+		l.continued = strings.HasSuffix(line, "\\")
+		return append(tokens, lexToken{Kind: tokenLineComment, End: len(line), Continued: true, Open: l.continued})
+	}
+
+	cur := lexToken{Kind: l.kind()}
+	cur.Continued = cur.Kind != tokenCode
+
+	flush := func(at int, next tokenKind) {
+		cur.End = at
+		if cur.End > cur.Start || cur.Continued {
+			tokens = append(tokens, cur)
+		}
+		cur = lexToken{Kind: next, Start: at}
+	}
+
+	escapedEOL := false
+	i := 0
+	for i < len(line) {
+		rest := line[i:]
+
+		if l.inBlock {
+			if l.depth > 1 && strings.HasPrefix(rest, l.lang.MultiLineEnd) {
+				i += len(l.lang.MultiLineEnd)
+				l.depth--
+			} else if strings.HasPrefix(rest, l.lang.MultiLineEnd) {
+				i += len(l.lang.MultiLineEnd)
+				l.leaveBlockComment()
+				flush(i, tokenCode)
+			} else if l.lang.NestedComments && strings.HasPrefix(rest, l.lang.MultiLineStart) {
+				i += len(l.lang.MultiLineStart)
+				l.depth++
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if n := len(l.frames); n > 0 && !l.frames[n-1].interp {
+			top := &l.frames[n-1]
+			switch {
+			case top.delim.Escape != 0 && line[i] == top.delim.Escape:
+				if i+1 >= len(line) {
+					escapedEOL = true
+				}
+				i += 2
+			case len(top.delim.Interpolation) == 1 && strings.HasPrefix(rest, top.delim.Interpolation+top.delim.Interpolation):
+				i += 2
+			case top.delim.Interpolation != "" && strings.HasPrefix(rest, top.delim.Interpolation):
+				top.enterCode(top.delim.Interpolation)
+				i += len(top.delim.Interpolation)
+			case substitutionStart(rest, top.delim) != "":
+				opener := substitutionStart(rest, top.delim)
+				top.enterCode(opener)
+				i += len(opener)
+			case strings.HasPrefix(rest, top.delim.End):
+				i += len(top.delim.End)
+				l.frames = l.frames[:n-1]
+				if len(l.frames) == 0 {
+					flush(i, tokenCode)
+				}
+			default:
+				i++
+			}
+			continue
+		}
+
+		interp := len(l.frames) > 0
+		if !interp {
+			if l.lang.SingleLineStart != "" && strings.HasPrefix(rest, l.lang.SingleLineStart) && (!l.lang.CommentsAtWordStart || atWordStart(line, i)) {
+				flush(i, tokenLineComment)
+				l.continued = l.lang.LineContinuation && strings.HasSuffix(line, "\\")
+				i = len(line)
+				break
+			}
+			if l.blockCommentStart(rest) {
+				flush(i, tokenBlockComment)
+				l.enterBlockComment()
+				i += len(l.lang.MultiLineStart)
+				continue
+			}
+			if l.lang.Heredocs {
+				if n := l.shellSyntax(rest); n > 0 {
+					i += n
+					continue
+				}
+			}
+		}
+
+		if interp {
+			top := &l.frames[len(l.frames)-1]
+			switch c := line[i]; {
+			case c == top.close && top.braces == 0:
+				top.interp = false
+				i++
+				continue
+			case c == top.close:
+				top.braces--
+			case c == top.open && c != 0:
+				top.braces++
+			}
+		}
+
+		if l.lang.Heredocs && line[i] == '\\' {
+			i += 2
+			continue
+		}
+
+		if delim, ok := l.stringStart(rest); ok {
+			if !interp {
+				flush(i, tokenString)
+			}
+			l.frames = append(l.frames, lexFrame{delim: delim})
+			i += len(delim.Start)
+			continue
+		}
+		i++
+	}
+
+	if len(l.frames) > 0 && !l.frames[0].delim.MultiLine && !escapedEOL {
+		l.frames = nil
+	}
+
+	cur.End = len(line)
+	cur.Open = l.inBlock || len(l.frames) > 0 || l.continued
+	if cur.End > cur.Start || cur.Continued || cur.Open {
+		tokens = append(tokens, cur)
+	}
+	return tokens
+}
+
+func (l *lexer) blockCommentStart(rest string) bool {
+	return l.lang.MultiLineStart != "" && l.lang.MultiLineEnd != "" && strings.HasPrefix(rest, l.lang.MultiLineStart)
+}
+
+func (l *lexer) stringStart(rest string) (StringDelimiter, bool) {
+	if !l.openers[rest[0]] {
+		return StringDelimiter{}, false
+	}
+	for _, delim := range l.delimiters {
+		if !strings.HasPrefix(rest, delim.Start) || delim.Char && !isCharLiteral(rest, delim) {
+			continue
+		}
+		if delim.Delimited {
+			return delimitedString(rest, delim)
+		}
+		return delim, true
+	}
+	return StringDelimiter{}, false
+}
+
+func substitutionStart(rest string, delim StringDelimiter) string {
+	for _, opener := range delim.Substitutions {
+		if strings.HasPrefix(rest, opener) {
+			return opener
+		}
+	}
+	return ""
+}
+
+// maxRawDelimiter is the longest d-char-sequence C++ allows in a raw string.
+const maxRawDelimiter = 16
+
+// delimitedString resolves a C++-style raw string R"tag(...)tag" to a
+// delimiter that starts after "tag(" and ends at ")tag" followed by End.
+func delimitedString(rest string, delim StringDelimiter) (StringDelimiter, bool) {
+	body := rest[len(delim.Start):]
+	open := strings.IndexByte(body, '(')
+	if open < 0 || open > maxRawDelimiter || strings.ContainsAny(body[:open], " \t\\)") {
+		return StringDelimiter{}, false
+	}
+	tag := body[:open]
+	delim.Start = rest[:len(delim.Start)+open+1]
+	delim.End = ")" + tag + delim.End
+	return delim, true
+}
+
+// isCharLiteral reports whether rest opens a character literal that closes
+// on the same line after one, possibly escaped, character.
+func isCharLiteral(rest string, delim StringDelimiter) bool {
+	body := rest[len(delim.Start):]
+	if body == "" {
+		return false
+	}
+	if delim.Escape != 0 && body[0] == delim.Escape {
+		return len(body) > 2 && strings.Contains(body[2:], delim.End)
+	}
+	_, size := utf8.DecodeRuneInString(body)
+	return strings.HasPrefix(body[size:], delim.End)
+}
+
+// appendHeredocLine adds line as part of the current here-document body.
+// The line that ends the body belongs to it as well.
+func (l *lexer) appendHeredocLine(tokens []lexToken, line string) []lexToken {
+	doc := l.heredocs[0]
+	text := line
+	if doc.stripTabs {
+		text = strings.TrimLeft(line, "\t")
+	}
+	open := text != doc.word
+	if !open {
+		l.heredocs = l.heredocs[1:]
+	}
+	return append(tokens, lexToken{Kind: tokenString, End: len(line), Continued: true, Open: open || len(l.heredocs) > 0})
+}
+
+// shellSyntax consumes the shell constructs that change how the following
+// lines lex: the (( )) of arithmetic, where << is a shift, and the operator
+// and word of a here-document, whose body is queued for the next lines.
+func (l *lexer) shellSyntax(rest string) int {
+	switch {
+	case strings.HasPrefix(rest, "(("):
+		l.arithmetic++
+		return 2
+	case strings.HasPrefix(rest, "))") && l.arithmetic > 0:
+		l.arithmetic--
+		return 2
+	case l.arithmetic > 0 || !strings.HasPrefix(rest, "<<") || strings.HasPrefix(rest, "<<<"):
+		return 0
+	}
+
+	i := 2
+	doc := heredoc{}
+	if i < len(rest) && rest[i] == '-' {
+		doc.stripTabs = true
+		i++
+	}
+	for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t') {
+		i++
+	}
+
+	var word strings.Builder
+scan:
+	for i < len(rest) {
+		switch c := rest[i]; {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(rest[i+1:], c)
+			if end < 0 {
+				return 0
+			}
+			word.WriteString(rest[i+1 : i+1+end])
+			i += end + 2
+		case c == '\\' && i+1 < len(rest):
+			word.WriteByte(rest[i+1])
+			i += 2
+		case strings.IndexByte(" \t;&|<>()", c) >= 0:
+			break scan
+		default:
+			word.WriteByte(c)
+			i++
+		}
+	}
+	if word.Len() == 0 {
+		return 0
+	}
+	doc.word = word.String()
+	l.heredocs = append(l.heredocs, doc)
+	return i
+}
+
+// atWordStart reports whether the byte at i begins a shell word, the only
+// place where # starts a comment.
+func atWordStart(line string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t;&|()<>", line[i-1]) >= 0
+}
+
+func lineCommentToken(tokens []lexToken) (lexToken, bool) {
+	for _, token := range tokens {
+		if token.Kind == tokenLineComment {
+			return token, true
+		}
+	}
+	return lexToken{}, false
+}
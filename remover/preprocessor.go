@@ -0,0 +1,118 @@
+package remover
+
+import "strings"
+
+// preprocessorDirective splits a C preprocessor line such as "# if 0" into
+// its name and the first word of its argument.
+func preprocessorDirective(line string, tokens []lexToken) (string, string, bool) {
+	if first, ok := firstSignificantToken(line, tokens); !ok || first.Kind != tokenCode || first.Continued {
+		return "", "", false
+	}
+	code := strings.TrimSpace(codeText(line, tokens))
+	rest, ok := strings.CutPrefix(code, "#")
+	if !ok {
+		return "", "", false
+	}
+	fields := strings.Fields(rest)
+	switch len(fields) {
+	case 0:
+		return "", "", false
+	case 1:
+		return fields[0], "", true
+	default:
+		return fields[0], fields[1], true
+	}
+}
+
+func opensConditional(name string) bool {
+	return name == "if" || name == "ifdef" || name == "ifndef"
+}
+
+// matchingEndif returns the #endif closing the conditional opened on line
+// start and the #else at the same level, or -1 for either when it is missing.
+// ok is false when the conditional has an #elif branch.
+func matchingEndif(lines []string, lineTokens [][]lexToken, start int) (endif, elseLine int, ok bool) {
+	depth, elseLine := 0, -1
+	for l := start + 1; l < len(lines); l++ {
+		name, _, isDirective := preprocessorDirective(lines[l], lineTokens[l])
+		switch {
+		case !isDirective:
+		case opensConditional(name):
+			depth++
+		case name == "endif" && depth == 0:
+			return l, elseLine, true
+		case name == "endif":
+			depth--
+		case depth == 0 && name == "else":
+			elseLine = l
+		case depth == 0 && strings.HasPrefix(name, "elif"):
+			return -1, -1, false
+		}
+	}
+	return -1, -1, false
+}
+
+// collectDisabledBlocks finds #if 0 ... #endif regions. When the region has an
+// #else branch, only the #if 0 part and the closing #endif line are returned,
+// leaving the live branch in place.
+func collectDisabledBlocks(lines []string, lineTokens [][]lexToken) []blockComment {
+	var blocks []blockComment
+	for i := 0; i < len(lines); i++ {
+		name, arg, ok := preprocessorDirective(lines[i], lineTokens[i])
+		if !ok || name != "if" || arg != "0" {
+			continue
+		}
+		endif, elseLine, ok := matchingEndif(lines, lineTokens, i)
+		if !ok || endif < 0 {
+			continue
+		}
+		if elseLine < 0 {
+			blocks = append(blocks, disabledBlock(lines, i, endif))
+		} else {
+			blocks = append(blocks, disabledBlock(lines, i, elseLine), disabledBlock(lines, endif, endif))
+		}
+		i = endif
+	}
+	return blocks
+}
+
+func disabledBlock(lines []string, start, end int) blockComment {
+	return blockComment{
+		startLine:  start,
+		startCol:   indentation(lines[start]),
+		endLine:    end,
+		endCol:     len(lines[end]),
+		text:       strings.TrimSpace(strings.Join(lines[start:end+1], "\n")),
+		kind:       CommentBlock,
+		wholeLines: true,
+	}
+}
+
+// intactPreprocessorLines returns the #pragma lines and the three lines of an
+// include guard, whose comments are kept along with them.
+func intactPreprocessorLines(lines []string, lineTokens [][]lexToken) map[int]bool {
+	intact := make(map[int]bool)
+	guard, guardName := -1, ""
+	for i := range lines {
+		if first, ok := firstSignificantToken(lines[i], lineTokens[i]); !ok || first.Kind != tokenCode {
+			continue
+		}
+		name, arg, _ := preprocessorDirective(lines[i], lineTokens[i])
+		switch {
+		case name == "pragma":
+			intact[i] = true
+		case guard == -1 && name == "ifndef":
+			guard, guardName = i, arg
+			continue
+		case guardName != "" && name == "define" && arg == guardName:
+			if endif, _, ok := matchingEndif(lines, lineTokens, guard); ok && endif >= 0 {
+				intact[guard], intact[i], intact[endif] = true, true, true
+			}
+		}
+		if guard == -1 {
+			guard = -2
+		}
+		guardName = ""
+	}
+	return intact
+}
@@ -0,0 +1,269 @@
+package remover
+
+import (
+	"slices"
+	"strings"
+)
+
+func docCommentLines(lines []string, lineTokens [][]lexToken, lang Language) map[int]bool {
+	preserved := make(map[int]bool)
+	if len(lang.DocComments) == 0 {
+		return preserved
+	}
+
+	for i := 0; i < len(lines); {
+		token, ok := leadingComment(lines[i], lineTokens[i])
+		if !ok {
+			i++
+			continue
+		}
+
+		rule, ok := matchDocRule(lines[i][token.Start:token.End], lang)
+		if !ok {
+			i++
+			continue
+		}
+
+		end := i
+		if token.Kind == tokenLineComment {
+			for end+1 < len(lines) {
+				next, ok := leadingComment(lines[end+1], lineTokens[end+1])
+				if !ok || next.Kind != tokenLineComment {
+					break
+				}
+				if _, ok := matchDocRule(lines[end+1][next.Start:next.End], lang); !ok {
+					break
+				}
+				end++
+			}
+		} else {
+			end, ok = blockCommentEnd(lines, lineTokens, i)
+			if !ok {
+				i++
+				continue
+			}
+		}
+
+		if rule.Inner || end+1 < len(lines) && isDeclarationLine(lines[end+1], lineTokens[end+1], rule) {
+			for l := i; l <= end; l++ {
+				preserved[l] = true
+			}
+		}
+		i = end + 1
+	}
+
+	return preserved
+}
+
+func leadingComment(line string, tokens []lexToken) (lexToken, bool) {
+	for _, token := range tokens {
+		if token.Kind == tokenCode && strings.TrimSpace(line[token.Start:token.End]) == "" {
+			continue
+		}
+		if token.Continued || (token.Kind != tokenLineComment && token.Kind != tokenBlockComment) {
+			return lexToken{}, false
+		}
+		return token, true
+	}
+	return lexToken{}, false
+}
+
+func blockCommentEnd(lines []string, lineTokens [][]lexToken, startLine int) (int, bool) {
+	for l := startLine; l < len(lines); l++ {
+		for _, token := range lineTokens[l] {
+			if token.Kind != tokenBlockComment || (l > startLine && !token.Continued) || token.Open {
+				continue
+			}
+			return l, strings.TrimSpace(lines[l][token.End:]) == ""
+		}
+	}
+	return 0, false
+}
+
+func matchDocRule(text string, lang Language) (DocCommentRule, bool) {
+	for _, rule := range lang.DocComments {
+		if !strings.HasPrefix(text, rule.Marker) {
+			continue
+		}
+		rest := text[len(rule.Marker):]
+		if strings.HasPrefix(rest, rule.Marker[len(rule.Marker)-1:]) || strings.HasPrefix(rest, "/") {
+			continue
+		}
+		return rule, true
+	}
+	return DocCommentRule{}, false
+}
+
+func isDeclarationLine(line string, tokens []lexToken, rule DocCommentRule) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	for _, token := range tokens {
+		if token.Kind == tokenCode && strings.TrimSpace(line[token.Start:token.End]) == "" {
+			continue
+		}
+		if token.Kind != tokenCode {
+			return false
+		}
+		break
+	}
+	return rule.Declaration == nil || rule.Declaration.MatchString(trimmed)
+}
+
+func isDirectiveComment(text string, lang Language) bool {
+	for _, directive := range lang.Directives {
+		if strings.HasPrefix(text, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+func directiveAnchorLines(lines []string, lineTokens [][]lexToken, lang Language) map[int]bool {
+	preserved := make(map[int]bool)
+	if len(lang.DirectiveAnchors) == 0 {
+		return preserved
+	}
+
+	for i, line := range lines {
+		if !slices.Contains(lang.DirectiveAnchors, strings.TrimSpace(line)) {
+			continue
+		}
+		for l := i - 1; l >= 0 && isCommentOnlyLine(lines[l], lineTokens[l]); l-- {
+			preserved[l] = true
+		}
+	}
+
+	return preserved
+}
+
+func isCommentOnlyLine(line string, tokens []lexToken) bool {
+	hasComment := false
+	for _, token := range tokens {
+		switch token.Kind {
+		case tokenLineComment, tokenBlockComment:
+			hasComment = true
+		case tokenCode:
+			if strings.TrimSpace(line[token.Start:token.End]) != "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return hasComment
+}
+
+const ControlPrefix = "commenter:"
+
+type ProtectedRegion struct {
+	Directive     string
+	DirectiveLine int
+	StartLine     int
+	EndLine       int
+	Protected     int
+}
+
+type controlDirectives struct {
+	lang       Language
+	regions    []ProtectedRegion
+	lineRegion map[int]int
+}
+
+func parseControlDirectives(lines []string, lineTokens [][]lexToken, lang Language) *controlDirectives {
+	control := &controlDirectives{lang: lang, lineRegion: make(map[int]int)}
+	open := -1
+
+	for i, tokens := range lineTokens {
+		for _, token := range tokens {
+			if token.Kind != tokenLineComment && token.Kind != tokenBlockComment {
+				continue
+			}
+			switch controlDirective(lines[i][token.Start:token.End], lang) {
+			case "disable":
+				if open < 0 {
+					control.regions = append(control.regions, ProtectedRegion{Directive: "disable", DirectiveLine: i + 1, StartLine: i + 1, EndLine: len(lines)})
+					open = len(control.regions) - 1
+				}
+			case "enable":
+				if open >= 0 {
+					control.regions[open].EndLine = i + 1
+					open = -1
+				}
+			case "keep":
+				region := ProtectedRegion{Directive: "keep", DirectiveLine: i + 1, StartLine: i + 1, EndLine: i + 1}
+				commentOnly := isCommentOnlyLine(lines[i], tokens)
+				if commentOnly && i+1 < len(lines) {
+					region.StartLine, region.EndLine = i+2, i+2
+				} else if !commentOnly && commentTokens(tokens) == 1 {
+					// At the end of a code line with no other comment, the
+					// directive is the comment it keeps.
+					region.Protected = 1
+				}
+				control.regions = append(control.regions, region)
+			}
+		}
+	}
+
+	// A keep is more specific than an enclosing disable region, so it gets
+	// the credit for the line it protects.
+	for idx, region := range control.regions {
+		for l := region.StartLine - 1; l < region.EndLine; l++ {
+			if _, ok := control.lineRegion[l]; !ok || region.Directive == "keep" {
+				control.lineRegion[l] = idx
+			}
+		}
+	}
+
+	return control
+}
+
+func commentTokens(tokens []lexToken) int {
+	n := 0
+	for _, token := range tokens {
+		if token.Kind == tokenLineComment || token.Kind == tokenBlockComment {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *controlDirectives) protects(startLine, endLine int, text string) bool {
+	if controlDirective(text, c.lang) != "" {
+		return true
+	}
+	for l := startLine; l <= endLine; l++ {
+		if idx, ok := c.lineRegion[l]; ok {
+			c.regions[idx].Protected++
+			return true
+		}
+	}
+	return false
+}
+
+func controlDirective(text string, lang Language) string {
+	body := CommentBody(text, lang)
+	if !strings.HasPrefix(body, ControlPrefix) {
+		return ""
+	}
+	for _, kind := range []string{"disable", "enable", "keep"} {
+		if strings.HasPrefix(body[len(ControlPrefix):], kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// CommentBody returns the text of a comment without the delimiters of lang.
+func CommentBody(text string, lang Language) string {
+	if lang.Docstrings {
+		if body, ok := docstringBody(text); ok {
+			return body
+		}
+	}
+	if lang.SingleLineStart != "" && strings.HasPrefix(text, lang.SingleLineStart) {
+		return strings.TrimSpace(text[len(lang.SingleLineStart):])
+	}
+	return blockCommentBody(text, lang)
+}
@@ -0,0 +1,791 @@
+package remover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const utf8BOM = "\xEF\xBB\xBF"
+
+// Result is the outcome of removing comments from one source. Edits,
+// ModifiedLines, ModifiedEndings and LineMap all come from the same per-line
+// outcome, so applying Edits to Original() gives Content().
+type Result struct {
+	OriginalLines    int
+	CommentsRemoved  int
+	RemainingLines   int
+	ModifiedLines    []string
+	ModifiedEndings  []string
+	SourceLines      []string
+	SourceEndings    []string
+	LineMap          []int
+	Edits            []Edit
+	RemovedComments  []RemovedComment
+	ProtectedRegions []ProtectedRegion
+	BOM              bool
+	TrailingNewline  bool
+}
+
+type RemovedComment struct {
+	LineNumber    int
+	EndLineNumber int
+	Column        int
+	EndColumn     int
+	Kind          CommentKind
+	Text          string
+	Content       string
+}
+
+type CommentKind string
+
+const (
+	CommentInline     CommentKind = "inline"
+	CommentStandalone CommentKind = "standalone"
+	CommentBlock      CommentKind = "block"
+	CommentDocstring  CommentKind = "docstring"
+)
+
+type blockComment struct {
+	startLine int
+	startCol  int
+	endLine   int
+	endCol    int
+	text      string
+	kind      CommentKind
+	// wholeLines marks a block, such as #if 0 ... #endif, that owns every
+	// line it spans, comments included.
+	wholeLines bool
+}
+
+type lineCut struct {
+	start int
+	end   int
+}
+
+// lineEdit is the outcome for one source line: either it is dropped together
+// with its line ending, or it is kept with text as its new content.
+type lineEdit struct {
+	text   string
+	remove bool
+}
+
+func keepLine(text string) lineEdit {
+	return lineEdit{text: text}
+}
+
+func process(source string, lang Language, options Options) (*Result, error) {
+	lang = ApplyPragmaOptions(lang, options)
+	consecutive := options.Consecutive
+	removeSingleLineMultiline := options.RemoveSingleLineMultiline && !options.RemoveBlocks
+	ignorePatterns, err := CompileIgnorePatterns(options.IgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.SkipMinified {
+		if err := checkMinified(source); err != nil {
+			return nil, err
+		}
+	}
+
+	allLines, allEndings := splitSourceLines(source)
+	hasBOM := len(allLines) > 0 && strings.HasPrefix(allLines[0], utf8BOM)
+	if hasBOM {
+		allLines[0] = allLines[0][len(utf8BOM):]
+	}
+	trailingNewline := len(allEndings) > 0 && allEndings[len(allEndings)-1] != ""
+
+	buffers := lexBufferPool.Get().(*lexBuffers)
+	defer buffers.release()
+	lineTokens := buffers.scan(newLexer(lang), allLines)
+	standalone := make([]bool, len(allLines))
+	// runs marks the standalone comments that can form a run of consecutive
+	// comments; a kept shebang, directive or pragma is not one.
+	runs := make([]bool, len(allLines))
+	for i, line := range allLines {
+		standalone[i] = isStandaloneLineComment(line, lineTokens[i])
+		runs[i] = standalone[i] && (options.StripDirectives || !isDirectiveLine(line, lineTokens[i], lang)) && !isPragmaLine(line, lineTokens[i], lang)
+	}
+
+	preserved := make(map[int]bool)
+	if options.KeepDocComments || lang.PreserveDocComments && !options.RemoveDocComments {
+		preserved = docCommentLines(allLines, lineTokens, lang)
+	}
+	if !options.StripDirectives {
+		for l := range directiveAnchorLines(allLines, lineTokens, lang) {
+			preserved[l] = true
+		}
+	}
+
+	control := parseControlDirectives(allLines, lineTokens, lang)
+
+	var intact map[int]bool
+	if lang.Preprocessor {
+		intact = intactPreprocessorLines(allLines, lineTokens)
+	}
+
+	var blocks []blockComment
+	if options.RemoveBlocks {
+		blocks = collectBlockComments(allLines, lineTokens, lang)
+	}
+	if options.RemoveDocstrings && lang.Docstrings {
+		blocks = append(blocks, collectDocstrings(allLines, lineTokens)...)
+	}
+	if options.RemoveIfZero && lang.Preprocessor {
+		blocks = append(blocks, collectDisabledBlocks(allLines, lineTokens)...)
+	}
+
+	blockCuts := make(map[int][]lineCut)
+	blocksByLine := make(map[int][]blockComment)
+	wholeLines := make(map[int]bool)
+	for _, block := range blocks {
+		ignoreLang := lang
+		if block.kind == CommentDocstring {
+			// Pragmas live in comments; docstrings often mention "type:".
+			ignoreLang.Pragmas = nil
+		}
+		if shouldIgnoreComment(block.text, ignoreLang, ignorePatterns) {
+			continue
+		}
+		if preserved[block.startLine] && strings.TrimSpace(allLines[block.startLine][:block.startCol]) == "" {
+			continue
+		}
+		if !options.StripDirectives && isDirectiveComment(block.text, lang) {
+			continue
+		}
+		if intact[block.startLine] {
+			continue
+		}
+		if control.protects(block.startLine, block.endLine, block.text) {
+			continue
+		}
+		blocksByLine[block.startLine] = append(blocksByLine[block.startLine], block)
+		for l := block.startLine; l <= block.endLine; l++ {
+			cut := lineCut{start: 0, end: len(allLines[l])}
+			if l == block.startLine {
+				cut.start = block.startCol
+			}
+			if l == block.endLine {
+				cut.end = block.endCol
+			}
+			blockCuts[l] = append(blockCuts[l], cut)
+			wholeLines[l] = block.wholeLines
+		}
+	}
+
+	lineEdits := make([]lineEdit, len(allLines))
+	var removedComments []RemovedComment
+	// continuation is the removed comment that a line comment ending in a
+	// backslash carries onto the next line, or -1 when it was kept.
+	continuation := -1
+
+	for i, line := range allLines {
+		lineNumber := i + 1
+		originalLine := line
+
+		for _, block := range blocksByLine[i] {
+			removedComments = append(removedComments, RemovedComment{
+				LineNumber:    block.startLine + 1,
+				EndLineNumber: block.endLine + 1,
+				Column:        block.startCol + 1,
+				EndColumn:     block.endCol + 1,
+				Kind:          block.kind,
+				Text:          block.text,
+				Content:       block.text,
+			})
+		}
+
+		if wholeLines[i] {
+			lineEdits[i] = lineEdit{remove: true}
+			continue
+		}
+
+		if token, ok := lineCommentToken(lineTokens[i]); ok && token.Continued {
+			lineEdits[i] = keepLine(line)
+			if continuation >= 0 {
+				comment := &removedComments[continuation]
+				comment.EndLineNumber = lineNumber
+				comment.EndColumn = len(line) + 1
+				comment.Text += "\n" + line
+				comment.Content += "\n" + line
+				lineEdits[i] = lineEdit{remove: true}
+			}
+			if !token.Open {
+				continuation = -1
+			}
+			continue
+		}
+
+		isConsecutive := standalone[i] && isPartOfConsecutiveComments(runs, i)
+
+		edit, removed := removeLineComment(line, lineTokens[i], consecutive, isConsecutive)
+
+		if removed && standalone[i] && preserved[i] {
+			removed = false
+			edit = keepLine(originalLine)
+		}
+
+		if removed && !options.StripDirectives {
+			if token, ok := lineCommentToken(lineTokens[i]); ok && isDirectiveComment(line[token.Start:], lang) {
+				removed = false
+				edit = keepLine(originalLine)
+			}
+		}
+
+		if removed {
+			if token, ok := lineCommentToken(lineTokens[i]); ok && shouldIgnoreComment(line[token.Start:], lang, ignorePatterns) {
+				removed = false
+				edit = keepLine(originalLine)
+			}
+		}
+
+		if removed {
+			if token, ok := lineCommentToken(lineTokens[i]); ok && control.protects(i, i, line[token.Start:]) {
+				removed = false
+				edit = keepLine(originalLine)
+			}
+		}
+
+		if removed && intact[i] {
+			removed = false
+			edit = keepLine(originalLine)
+		}
+
+		removedComment := RemovedComment{LineNumber: lineNumber, EndLineNumber: lineNumber}
+		if token, ok := lineCommentToken(lineTokens[i]); ok && removed {
+			removedComment.Column = token.Start + 1
+			removedComment.EndColumn = len(line) + 1
+			removedComment.Text = line[token.Start:]
+			removedComment.Kind = CommentInline
+			if standalone[i] {
+				removedComment.Kind = CommentStandalone
+			}
+		}
+
+		if !removed && removeSingleLineMultiline && !preserved[i] {
+			singleLine, content := singleLineBlockComment(line, lineTokens[i], lang)
+			if !singleLine && len(lang.AdditionalMultiLinePatterns) > 0 {
+				singleLine, content = singleLineBracedComment(allLines, lineTokens, i, lang)
+			}
+			if singleLine {
+				if shouldIgnoreComment(content, lang, ignorePatterns) || control.protects(i, i, strings.TrimSpace(content)) {
+					removed = false
+					edit = keepLine(content)
+				} else {
+					removed = true
+					edit = lineEdit{remove: true}
+					originalLine = content
+					removedComment.Kind = CommentBlock
+					removedComment.Text = strings.TrimSpace(content)
+					removedComment.Column = strings.Index(content, removedComment.Text) + 1
+					removedComment.EndColumn = removedComment.Column + len(removedComment.Text)
+				}
+			}
+		}
+
+		if removed {
+			removedComment.Content = originalLine
+			removedComments = append(removedComments, removedComment)
+		}
+		if token, ok := lineCommentToken(lineTokens[i]); ok && token.Open {
+			continuation = -1
+			if removed && removedComment.Text != "" {
+				continuation = len(removedComments) - 1
+			}
+		}
+
+		if cuts := blockCuts[i]; len(cuts) > 0 {
+			if token, ok := lineCommentToken(lineTokens[i]); ok && removed {
+				cuts = append(cuts, lineCut{start: token.Start, end: len(line)})
+			}
+			edit = keepLine(applyLineCuts(line, cuts))
+			if strings.TrimSpace(edit.text) == "" {
+				edit = lineEdit{remove: true}
+			}
+		}
+
+		lineEdits[i] = edit
+	}
+
+	result := &Result{
+		OriginalLines:    len(allLines),
+		CommentsRemoved:  len(removedComments),
+		SourceLines:      allLines,
+		SourceEndings:    allEndings,
+		RemovedComments:  removedComments,
+		ProtectedRegions: control.regions,
+		BOM:              hasBOM,
+		TrailingNewline:  trailingNewline,
+	}
+	result.Edits = buildEdits(result, lineEdits)
+	result.applyLineEdits(lineEdits)
+	return result, nil
+}
+
+var ErrMinified = errors.New("minified file")
+
+const (
+	minifiedMinSize       = 4 << 10
+	minifiedAvgLineLength = 300
+)
+
+// checkMinified flags bundles and single-line exports: files of some size
+// without any newline, or whose lines are unusually long on average.
+func checkMinified(source string) error {
+	if len(source) < minifiedMinSize {
+		return nil
+	}
+	newlines := strings.Count(source, "\n")
+	if newlines == 0 {
+		return fmt.Errorf("%w: %d bytes on a single line", ErrMinified, len(source))
+	}
+	if avg := len(source) / (newlines + 1); avg > minifiedAvgLineLength {
+		return fmt.Errorf("%w: average line length %d", ErrMinified, avg)
+	}
+	return nil
+}
+
+// readChunkSize bounds the buffer files are streamed through.
+const readChunkSize = 32 << 10
+
+var readBufferPool = sync.Pool{
+	New: func() any {
+		chunk := make([]byte, readChunkSize)
+		return &chunk
+	},
+}
+
+// readSource streams filePath through a pooled chunk into a string builder
+// sized from the file's length. The Result keeps the text, so the builder is
+// the only per-file buffer and becomes the string without a copy.
+func readSource(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var source strings.Builder
+	if info, err := file.Stat(); err == nil {
+		source.Grow(int(info.Size()))
+	}
+
+	chunk := readBufferPool.Get().(*[]byte)
+	defer readBufferPool.Put(chunk)
+	for {
+		n, err := file.Read(*chunk)
+		source.Write((*chunk)[:n])
+		if err == io.EOF {
+			return source.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func splitSourceLines(source string) ([]string, []string) {
+	n := strings.Count(source, "\n") + 1
+	lines := make([]string, 0, n)
+	endings := make([]string, 0, n)
+	for len(source) > 0 {
+		end := len(source)
+		if i := strings.IndexByte(source, '\n'); i >= 0 {
+			end = i + 1
+		}
+		line, ending := splitLineEnding(source[:end])
+		lines = append(lines, line)
+		endings = append(endings, ending)
+		source = source[end:]
+	}
+	return lines, endings
+}
+
+// maxPooledTokens keeps one huge file from pinning its token buffers in the
+// pool.
+const maxPooledTokens = 1 << 20
+
+// lexBuffers holds the tokens of one process call. They never reach the
+// Result, so they are pooled and reused from file to file.
+type lexBuffers struct {
+	tokens     []lexToken
+	bounds     []int
+	lineTokens [][]lexToken
+}
+
+var lexBufferPool = sync.Pool{New: func() any { return new(lexBuffers) }}
+
+func (b *lexBuffers) release() {
+	if cap(b.tokens) > maxPooledTokens || cap(b.lineTokens) > maxPooledTokens {
+		return
+	}
+	clear(b.lineTokens)
+	lexBufferPool.Put(b)
+}
+
+// scan lexes every line into one shared token slice and hands out per-line
+// windows of it, instead of allocating a slice per line.
+func (b *lexBuffers) scan(lex *lexer, lines []string) [][]lexToken {
+	b.tokens = b.tokens[:0]
+	b.bounds = append(b.bounds[:0], 0)
+	for _, line := range lines {
+		b.tokens = lex.appendLine(b.tokens, line)
+		b.bounds = append(b.bounds, len(b.tokens))
+	}
+
+	b.lineTokens = slices.Grow(b.lineTokens[:0], len(lines))[:len(lines)]
+	for i := range lines {
+		b.lineTokens[i] = b.tokens[b.bounds[i]:b.bounds[i+1]:b.bounds[i+1]]
+	}
+	return b.lineTokens
+}
+
+func splitLineEnding(line string) (string, string) {
+	switch {
+	case strings.HasSuffix(line, "\r\n"):
+		return line[:len(line)-2], "\r\n"
+	case strings.HasSuffix(line, "\n"):
+		return line[:len(line)-1], "\n"
+	default:
+		return line, ""
+	}
+}
+
+func (r Result) Content() []byte {
+	return r.render(r.ModifiedLines, r.ModifiedEndings)
+}
+
+func (r Result) Original() []byte {
+	return r.render(r.SourceLines, r.SourceEndings)
+}
+
+func (r Result) render(lines, endings []string) []byte {
+	size := len(utf8BOM)
+	for _, line := range lines {
+		size += len(line) + len("\r\n")
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(size)
+	if r.BOM {
+		buf.WriteString(utf8BOM)
+	}
+
+	for i, line := range lines {
+		buf.WriteString(line)
+		last := i == len(lines)-1
+		switch {
+		case i < len(endings) && (endings[i] != "" || last):
+			buf.WriteString(endings[i])
+		case !last || r.TrailingNewline:
+			buf.WriteString("\n")
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func (r Result) UnusedDirectives() []ProtectedRegion {
+	var unused []ProtectedRegion
+	for _, region := range r.ProtectedRegions {
+		if region.Protected == 0 {
+			unused = append(unused, region)
+		}
+	}
+	return unused
+}
+
+func UpdateMultiLineCommentState(line string, lang Language, currentState bool) bool {
+	depth := 0
+	if currentState {
+		depth = 1
+	}
+	return UpdateMultiLineCommentDepth(line, lang, depth) > 0
+}
+
+// UpdateMultiLineCommentDepth returns how many block comments are still open
+// after line, given depth open before it. Only languages with NestedComments
+// go deeper than one.
+func UpdateMultiLineCommentDepth(line string, lang Language, depth int) int {
+	if lang.MultiLineStart == "" || lang.MultiLineEnd == "" {
+		return 0
+	}
+
+	lex := newLexer(lang)
+	if depth > 0 {
+		lex.enterBlockComment()
+		lex.depth = depth
+	}
+	lex.scanLine(line)
+
+	return lex.blockDepth()
+}
+
+func collectBlockComments(lines []string, lineTokens [][]lexToken, lang Language) []blockComment {
+	var blocks []blockComment
+	var current *blockComment
+	var text strings.Builder
+
+	for i, tokens := range lineTokens {
+		for _, token := range tokens {
+			if token.Kind != tokenBlockComment {
+				continue
+			}
+			if !token.Continued || current == nil {
+				current = &blockComment{startLine: i, startCol: token.Start, kind: CommentBlock}
+				text.Reset()
+			} else {
+				text.WriteByte('\n')
+			}
+			text.WriteString(lines[i][token.Start:token.End])
+			if token.Open {
+				continue
+			}
+			current.endLine = i
+			current.endCol = token.End
+			current.text = text.String()
+			widenBracePattern(current, lines, lineTokens, lang)
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	return blocks
+}
+
+// widenBracePattern extends block over the braces of a JSX {/* ... */}
+// comment, so that removing it does not leave an empty {} behind. The braces
+// must hold nothing but the comment, stand alone on their lines and follow a
+// tag or another {...} child; elsewhere, as in function f() {/* noop */},
+// they are code.
+func widenBracePattern(block *blockComment, lines []string, lineTokens [][]lexToken, lang Language) {
+	for _, pattern := range lang.AdditionalMultiLinePatterns {
+		openBrace, _ := strings.CutSuffix(pattern.Start, lang.MultiLineStart)
+		closeBrace, _ := strings.CutPrefix(pattern.End, lang.MultiLineEnd)
+		before, after := lines[block.startLine][:block.startCol], lines[block.endLine][block.endCol:]
+		if openBrace == "" || closeBrace == "" || strings.TrimSpace(before) != openBrace || strings.TrimSpace(after) != closeBrace {
+			continue
+		}
+		if !inJSXChildPosition(lines, lineTokens, block.startLine) {
+			return
+		}
+		block.startCol = strings.Index(before, openBrace)
+		block.endCol += strings.Index(after, closeBrace) + len(closeBrace)
+		block.text = openBrace + block.text + closeBrace
+		return
+	}
+}
+
+// inJSXChildPosition reports whether the code before line ends with a tag
+// (but not an arrow function's =>) or with another {...} child.
+func inJSXChildPosition(lines []string, lineTokens [][]lexToken, line int) bool {
+	for l := line - 1; l >= 0; l-- {
+		code := strings.TrimSpace(codeText(lines[l], lineTokens[l]))
+		if code == "" {
+			continue
+		}
+		return strings.HasSuffix(code, "}") || strings.HasSuffix(code, ">") && !strings.HasSuffix(code, "=>")
+	}
+	return false
+}
+
+// singleLineBracedComment is singleLineBlockComment for a whole-line JSX
+// {/* ... */} comment.
+func singleLineBracedComment(lines []string, lineTokens [][]lexToken, line int, lang Language) (bool, string) {
+	var block *blockComment
+	for _, token := range lineTokens[line] {
+		if token.Kind != tokenBlockComment {
+			continue
+		}
+		if block != nil || token.Continued || token.Open {
+			return false, ""
+		}
+		text := lines[line][token.Start:token.End]
+		block = &blockComment{startLine: line, startCol: token.Start, endLine: line, endCol: token.End, text: text}
+	}
+	if block == nil || blockCommentBody(block.text, lang) == "" {
+		return false, ""
+	}
+	widenBracePattern(block, lines, lineTokens, lang)
+	if strings.TrimSpace(lines[line][:block.startCol]) != "" || strings.TrimSpace(lines[line][block.endCol:]) != "" {
+		return false, ""
+	}
+	return true, lines[line]
+}
+
+func applyLineCuts(line string, cuts []lineCut) string {
+	sort.Slice(cuts, func(a, b int) bool { return cuts[a].start < cuts[b].start })
+
+	result := line[:cuts[0].start]
+	for i, cut := range cuts {
+		next := len(line)
+		if i+1 < len(cuts) {
+			next = cuts[i+1].start
+		}
+		result = joinAroundRemoval(result, line[cut.end:next])
+	}
+	return result
+}
+
+func joinAroundRemoval(left, right string) string {
+	trimmedLeft := strings.TrimRight(left, " \t")
+	trimmedRight := strings.TrimLeft(right, " \t")
+
+	if trimmedRight == "" {
+		return trimmedLeft
+	}
+	if trimmedLeft == "" {
+		return left + trimmedRight
+	}
+
+	last := trimmedLeft[len(trimmedLeft)-1]
+	first := trimmedRight[0]
+	switch {
+	case strings.IndexByte("([{", last) >= 0 || strings.IndexByte(")]},;", first) >= 0:
+		return trimmedLeft + trimmedRight
+	case left != trimmedLeft || right != trimmedRight, isWordByte(last) && isWordByte(first):
+		return trimmedLeft + " " + trimmedRight
+	default:
+		return trimmedLeft + trimmedRight
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+func isStandaloneLineComment(line string, tokens []lexToken) bool {
+	token, ok := lineCommentToken(tokens)
+	return ok && strings.TrimSpace(line[:token.Start]) == ""
+}
+
+func isDirectiveLine(line string, tokens []lexToken, lang Language) bool {
+	token, ok := lineCommentToken(tokens)
+	return ok && isDirectiveComment(line[token.Start:], lang)
+}
+
+func isPragmaLine(line string, tokens []lexToken, lang Language) bool {
+	token, ok := lineCommentToken(tokens)
+	return ok && isPragmaComment(line[token.Start:], lang)
+}
+
+func isPartOfConsecutiveComments(standalone []bool, currentIndex int) bool {
+	if currentIndex < 0 || currentIndex >= len(standalone) || !standalone[currentIndex] {
+		return false
+	}
+
+	hasPreviousComment := currentIndex > 0 && standalone[currentIndex-1]
+	hasNextComment := currentIndex < len(standalone)-1 && standalone[currentIndex+1]
+
+	return hasPreviousComment || hasNextComment
+}
+
+// RemoveSingleLineComment returns the line without its trailing comment. A
+// line that held nothing but the comment comes back empty, meaning the whole
+// line should be dropped.
+func RemoveSingleLineComment(line string, lang Language, inMultiLineComment bool, consecutive bool, isConsecutive bool) (string, bool) {
+	lex := newLexer(lang)
+	if inMultiLineComment {
+		lex.enterBlockComment()
+	}
+	tokens := lex.scanLine(line)
+	if token, ok := lineCommentToken(tokens); ok && isDirectiveComment(line[token.Start:], lang) {
+		return line, false
+	}
+	edit, removed := removeLineComment(line, tokens, consecutive, isConsecutive)
+	return edit.text, removed
+}
+
+func removeLineComment(line string, tokens []lexToken, consecutive bool, isConsecutive bool) (lineEdit, bool) {
+	token, ok := lineCommentToken(tokens)
+	if !ok {
+		return keepLine(line), false
+	}
+
+	beforeComment := strings.TrimRightFunc(line[:token.Start], func(r rune) bool {
+		return r == ' ' || r == '\t'
+	})
+
+	if strings.TrimSpace(beforeComment) == "" {
+		if isConsecutive && !consecutive {
+			return keepLine(line), false
+		}
+		return lineEdit{remove: true}, true
+	}
+
+	return keepLine(beforeComment), true
+}
+
+func IsInsideStringLiteral(line string, pos int) bool {
+	return IsInsideString(line, pos, Language{})
+}
+
+// IsInsideString reports whether the byte at pos is part of a string literal
+// of lang, such as a Python triple-quoted, raw or f-string.
+func IsInsideString(line string, pos int, lang Language) bool {
+	for _, token := range newLexer(lang).scanLine(line) {
+		if token.Kind == tokenString && token.Start < pos && (pos < token.End || token.Open) {
+			return true
+		}
+	}
+	return false
+}
+
+func RemoveSingleLineMultilineComment(line string, lang Language) (bool, string) {
+	return singleLineBlockComment(line, newLexer(lang).scanLine(line), lang)
+}
+
+func singleLineBlockComment(line string, tokens []lexToken, lang Language) (bool, string) {
+	var block *lexToken
+	for i := range tokens {
+		token := &tokens[i]
+		switch token.Kind {
+		case tokenBlockComment:
+			if block != nil || token.Continued || token.Open {
+				return false, ""
+			}
+			block = token
+		case tokenCode:
+			if strings.TrimSpace(line[token.Start:token.End]) != "" {
+				return false, ""
+			}
+		default:
+			return false, ""
+		}
+	}
+
+	if block != nil && blockCommentBody(line[block.Start:block.End], lang) != "" {
+		return true, line
+	}
+	return false, ""
+}
+
+func blockCommentBody(text string, lang Language) string {
+	for _, pattern := range lang.AdditionalMultiLinePatterns {
+		if strings.HasPrefix(text, pattern.Start) && strings.HasSuffix(text, pattern.End) && len(text) >= len(pattern.Start)+len(pattern.End) {
+			return strings.TrimSpace(text[len(pattern.Start) : len(text)-len(pattern.End)])
+		}
+	}
+	text = strings.TrimPrefix(text, lang.MultiLineStart)
+	text = strings.TrimSuffix(text, lang.MultiLineEnd)
+	return strings.TrimSpace(text)
+}
+
+// ApplyPragmaOptions returns lang with the pragma catalog disabled or
+// extended as requested by options.
+func ApplyPragmaOptions(lang Language, options Options) Language {
+	if options.DisablePragmas {
+		lang.Pragmas = nil
+	}
+	if extra := options.ExtraPragmas[LanguageKey(lang)]; len(extra) > 0 {
+		lang.Pragmas = append(slices.Clip(lang.Pragmas), extra...)
+	}
+	return lang
+}
@@ -0,0 +1,49 @@
+// Package remover removes comments from source code. It works on in-memory
+// buffers with Remove, or on files with ProcessFile and DiscoverFiles, and
+// reports every change as byte-range edits alongside the rewritten lines.
+package remover
+
+// Options selects which comments are removed. The zero value removes
+// single-line comments that are not part of a consecutive run and keeps
+// directives, pragmas and block comments. Minified input is processed like
+// any other unless SkipMinified is set.
+type Options struct {
+	Consecutive               bool
+	RemoveSingleLineMultiline bool
+	IgnorePatterns            []string
+	RemoveBlocks              bool
+	RemoveDocstrings          bool
+	KeepDocComments           bool
+	RemoveDocComments         bool
+	RemoveIfZero              bool
+	StripDirectives           bool
+	DisablePragmas            bool
+	ExtraPragmas              map[string][]string
+	// SkipMinified makes Remove and ProcessFile return ErrMinified for
+	// input that looks minified instead of processing it.
+	SkipMinified bool
+}
+
+// Remove removes comments from src, which is written in lang. src is not
+// modified; the processed content is available from Result.Content.
+func Remove(src []byte, lang Language, opts Options) (Result, error) {
+	result, err := process(string(src), lang, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return *result, nil
+}
+
+// ProcessFile reads filePath and removes its comments like Remove. The file
+// itself is left untouched.
+func ProcessFile(filePath string, lang Language, opts Options) (Result, error) {
+	source, err := readSource(filePath)
+	if err != nil {
+		return Result{}, err
+	}
+	result, err := process(source, lang, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return *result, nil
+}
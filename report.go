@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ur-wesley/commentRemover/remover"
+)
+
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+	FormatCheckstyle = "checkstyle"
+	FormatJUnit      = "junit"
+)
+
+var outputFormats = []string{FormatText, FormatJSON, FormatSARIF, FormatCheckstyle, FormatJUnit}
+
+// formatList names the output formats for usage and error messages.
+func formatList() string {
+	return strings.Join(outputFormats, ", ")
+}
+
+func checkOutputFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("unsupported output format: %s (expected one of %s)", format, formatList())
+	}
+	return nil
+}
+
+type jsonReport struct {
+	Files   []jsonFile    `json:"files"`
+	Skipped []SkippedFile `json:"skipped"`
+	Errors  []string      `json:"errors"`
+	Stats   jsonStats     `json:"stats"`
+}
+
+type jsonFile struct {
+	Path            string         `json:"path"`
+	Language        string         `json:"language"`
+	LanguageKey     string         `json:"languageKey"`
+	OriginalLines   int            `json:"originalLines"`
+	RemainingLines  int            `json:"remainingLines"`
+	CommentsRemoved int            `json:"commentsRemoved"`
+	Comments        []jsonComment  `json:"comments"`
+	Edits           []remover.Edit `json:"edits"`
+}
+
+type jsonComment struct {
+	Line      int                 `json:"line"`
+	EndLine   int                 `json:"endLine"`
+	Column    int                 `json:"column"`
+	EndColumn int                 `json:"endColumn"`
+	Kind      remover.CommentKind `json:"kind"`
+	Text      string              `json:"text"`
+}
+
+type jsonStats struct {
+	FilesProcessed   int `json:"filesProcessed"`
+	FilesSkipped     int `json:"filesSkipped"`
+	TotalComments    int `json:"totalComments"`
+	TotalLines       int `json:"totalLines"`
+	SuccessfulWrites int `json:"successfulWrites"`
+	FailedWrites     int `json:"failedWrites"`
+}
+
+func writeReport(w io.Writer, options ProcessingOptions, stats *ProcessingStats) error {
+	switch options.Format {
+	case FormatJSON:
+		return writeJSONReport(w, stats)
+	case FormatSARIF:
+		return writeSARIFReport(w, stats, options.Check)
+	case FormatCheckstyle:
+		return writeCheckstyleReport(w, stats)
+	case FormatJUnit:
+		return writeJUnitReport(w, stats)
+	default:
+		return fmt.Errorf("unsupported output format: %s", options.Format)
+	}
+}
+
+func writeJSONReport(w io.Writer, stats *ProcessingStats) error {
+	report := jsonReport{
+		Files:   make([]jsonFile, 0, len(stats.Results)),
+		Skipped: stats.Skipped,
+		Errors:  stats.Errors,
+		Stats: jsonStats{
+			FilesProcessed:   stats.FilesProcessed,
+			FilesSkipped:     stats.FilesSkipped,
+			TotalComments:    stats.TotalComments,
+			TotalLines:       stats.TotalLines,
+			SuccessfulWrites: stats.SuccessfulWrites,
+			FailedWrites:     stats.FailedWrites,
+		},
+	}
+	if report.Skipped == nil {
+		report.Skipped = []SkippedFile{}
+	}
+	if report.Errors == nil {
+		report.Errors = []string{}
+	}
+
+	for _, fr := range stats.Results {
+		if fr.Result == nil {
+			continue
+		}
+		file := jsonFile{
+			Path:            fr.File.Path,
+			Language:        fr.File.Language.Name,
+			LanguageKey:     remover.LanguageKey(fr.File.Language),
+			OriginalLines:   fr.Result.OriginalLines,
+			RemainingLines:  fr.Result.RemainingLines,
+			CommentsRemoved: fr.Result.CommentsRemoved,
+			Comments:        make([]jsonComment, 0, len(fr.Result.RemovedComments)),
+			Edits:           fr.Result.Edits,
+		}
+		if file.Edits == nil {
+			file.Edits = []remover.Edit{}
+		}
+		for _, comment := range fr.Result.RemovedComments {
+			file.Comments = append(file.Comments, jsonComment{
+				Line:      comment.LineNumber,
+				EndLine:   comment.EndLineNumber,
+				Column:    comment.Column,
+				EndColumn: comment.EndColumn,
+				Kind:      comment.Kind,
+				Text:      comment.Text,
+			})
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func unattributedErrors(stats *ProcessingStats) []string {
+	attributed := make(map[string]bool, len(stats.Results))
+	for _, fr := range stats.Results {
+		if fr.Error != "" {
+			attributed[fr.Error] = true
+		}
+	}
+
+	var errs []string
+	for _, err := range stats.Errors {
+		if !attributed[err] {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func commentSummary(comment remover.RemovedComment) string {
+	return strings.TrimSpace(strings.SplitN(comment.Text, "\n", 2)[0])
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ur-wesley/commentRemover/remover"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+var commentRules = []sarifRule{
+	{ID: "inline-comment", Name: "InlineComment", ShortDescription: sarifMessage{Text: "Comment at the end of a code line"}, DefaultConfig: sarifConfig{Level: "note"}},
+	{ID: "standalone-comment", Name: "StandaloneComment", ShortDescription: sarifMessage{Text: "Comment on a line of its own"}, DefaultConfig: sarifConfig{Level: "note"}},
+	{ID: "block-comment", Name: "BlockComment", ShortDescription: sarifMessage{Text: "Block comment"}, DefaultConfig: sarifConfig{Level: "note"}},
+	{ID: "commented-out-code", Name: "CommentedOutCode", ShortDescription: sarifMessage{Text: "Comment that contains disabled source code"}, DefaultConfig: sarifConfig{Level: "warning"}},
+	{ID: "docstring", Name: "Docstring", ShortDescription: sarifMessage{Text: "Docstring"}, DefaultConfig: sarifConfig{Level: "note"}},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	ColumnKind  string            `json:"columnKind"`
+	Results     []sarifResult     `json:"results"`
+	Invocations []sarifInvocation `json:"invocations"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	ByteOffset  *int          `json:"byteOffset,omitempty"`
+	ByteLength  *int          `json:"byteLength,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool                `json:"executionSuccessful"`
+	Notifications       []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+func writeSARIFReport(w io.Writer, stats *ProcessingStats, withFixes bool) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "commenter",
+			InformationURI: "https://github.com/ur-wesley/commentRemover",
+			Version:        getVersionFromPackageJSON(),
+			Rules:          commentRules,
+		}},
+		ColumnKind:  "utf16CodeUnits",
+		Results:     []sarifResult{},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: len(stats.Errors) == 0}},
+	}
+
+	for _, err := range stats.Errors {
+		run.Invocations[0].Notifications = append(run.Invocations[0].Notifications, sarifNotification{Level: "error", Message: sarifMessage{Text: err}})
+	}
+	for _, skipped := range stats.Skipped {
+		run.Invocations[0].Notifications = append(run.Invocations[0].Notifications, sarifNotification{Level: "warning", Message: sarifMessage{Text: fmt.Sprintf("Skipped %s: %s", skipped.Path, skipped.Reason)}})
+	}
+
+	for _, fr := range stats.Results {
+		if fr.Result == nil {
+			continue
+		}
+		uri := relativeSlashPath(fr.File.Path)
+		for i, comment := range fr.Result.RemovedComments {
+			ruleIndex := commentRuleIndex(comment, fr.File.Language)
+			rule := commentRules[ruleIndex]
+			result := sarifResult{
+				RuleID:    rule.ID,
+				RuleIndex: ruleIndex,
+				Level:     rule.DefaultConfig.Level,
+				Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", rule.ShortDescription.Text, commentSummary(comment))},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+					Region:           commentRegion(fr.Result, comment),
+				}}},
+			}
+			if replacements := commentReplacements(fr.Result, i); withFixes && len(replacements) > 0 {
+				result.Fixes = []sarifFix{{
+					Description: sarifMessage{Text: "Remove the comment"},
+					ArtifactChanges: []sarifArtifactChange{{
+						ArtifactLocation: sarifArtifactLocation{URI: uri},
+						Replacements:     replacements,
+					}},
+				}}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+func commentRuleIndex(comment remover.RemovedComment, lang remover.Language) int {
+	switch {
+	case comment.Kind == remover.CommentDocstring:
+		return 4
+	case isCommentedOutCode(comment.Text, lang):
+		return 3
+	case comment.Kind == remover.CommentInline:
+		return 0
+	case comment.Kind == remover.CommentStandalone:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func commentRegion(result *remover.Result, comment remover.RemovedComment) sarifRegion {
+	return sarifRegion{
+		StartLine:   comment.LineNumber,
+		StartColumn: utf16Column(result.SourceLines[comment.LineNumber-1], comment.Column),
+		EndLine:     comment.EndLineNumber,
+		EndColumn:   utf16Column(result.SourceLines[comment.EndLineNumber-1], comment.EndColumn),
+		Snippet:     &sarifMessage{Text: comment.Text},
+	}
+}
+
+// commentReplacements converts the edits attributed to a comment into SARIF
+// replacements. Edits never overlap, so applying the fixes of every result
+// yields the --write content.
+func commentReplacements(result *remover.Result, comment int) []sarifReplacement {
+	var replacements []sarifReplacement
+	for _, edit := range result.Edits {
+		if edit.Comment != comment {
+			continue
+		}
+		offset, length := edit.Start.Offset, edit.End.Offset-edit.Start.Offset
+		replacements = append(replacements, sarifReplacement{
+			DeletedRegion:   sarifRegion{ByteOffset: &offset, ByteLength: &length},
+			InsertedContent: sarifMessage{Text: edit.Replacement},
+		})
+	}
+	return replacements
+}
+
+func utf16Column(line string, column int) int {
+	end := min(max(column-1, 0), len(line))
+	units := 0
+	for _, r := range line[:end] {
+		units += utf16.RuneLen(r)
+	}
+	return units + 1
+}
+
+var codeLikePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[;{}]$`),
+	regexp.MustCompile(`^[\w.$\[\]]+\s*(:=|[-+*/]?=)\s*\S`),
+	regexp.MustCompile(`^[\w.$]+\(.*\)[;,]?$`),
+	regexp.MustCompile(`^(if|for|while|switch|return|func|function|var|let|const|import|package|class|def|public|private|protected)\b.*[\w)\]}'"]$`),
+}
+
+func isCommentedOutCode(text string, lang remover.Language) bool {
+	lines := 0
+	for _, line := range strings.Split(remover.CommentBody(text, lang), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if line == "" {
+			continue
+		}
+		lines++
+		matched := false
+		for _, pattern := range codeLikePatterns {
+			if pattern.MatchString(line) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return lines > 0
+}
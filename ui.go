@@ -2,10 +2,15 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/ur-wesley/commentRemover/remover"
 )
 
 const (
@@ -21,8 +26,16 @@ const (
 	ColorDim    = "\033[2m"
 )
 
+// reportOutput receives the human-readable report. Filter mode moves it to
+// stderr so that stdout carries only the processed source.
+var reportOutput io.Writer = os.Stdout
+
 func isTerminal() bool {
-	if fileInfo, _ := os.Stdout.Stat(); (fileInfo.Mode() & os.ModeCharDevice) != 0 {
+	file, ok := reportOutput.(*os.File)
+	if !ok {
+		return false
+	}
+	if fileInfo, _ := file.Stat(); (fileInfo.Mode() & os.ModeCharDevice) != 0 {
 		return true
 	}
 	return false
@@ -42,21 +55,21 @@ func printError(useColor bool, format string, args ...interface{}) {
 
 func printSuccess(useColor bool, format string, args ...interface{}) {
 	prefix := fmt.Sprintf("%s✓%s ", colorize(useColor, ColorGreen+ColorBold), colorize(useColor, ColorReset))
-	fmt.Printf(prefix+format+"\n", args...)
+	fmt.Fprintf(reportOutput, prefix+format+"\n", args...)
 }
 
 func printInfo(useColor bool, format string, args ...interface{}) {
 	prefix := fmt.Sprintf("%s📁%s ", colorize(useColor, ColorBlue), colorize(useColor, ColorReset))
-	fmt.Printf(prefix+format+"\n", args...)
+	fmt.Fprintf(reportOutput, prefix+format+"\n", args...)
 }
 
 func printWarning(useColor bool, format string, args ...interface{}) {
 	prefix := fmt.Sprintf("%s⚠%s ", colorize(useColor, ColorYellow+ColorBold), colorize(useColor, ColorReset))
-	fmt.Printf(prefix+format+"\n", args...)
+	fmt.Fprintf(reportOutput, prefix+format+"\n", args...)
 }
 
 func printStat(useColor bool, label string, value int) {
-	fmt.Printf("%s%s:%s %s%d%s\n",
+	fmt.Fprintf(reportOutput, "%s%s:%s %s%d%s\n",
 		colorize(useColor, ColorCyan),
 		label,
 		colorize(useColor, ColorReset),
@@ -68,32 +81,51 @@ func printStat(useColor bool, label string, value int) {
 func showHelpMessage(useColor bool) {
 	programName := filepath.Base(os.Args[0])
 
-	fmt.Printf("%s%s%s - Comment Remover\n", colorize(useColor, ColorBold+ColorBlue), programName, colorize(useColor, ColorReset))
-	fmt.Printf("A performant CLI tool that safely removes single-line comments from source code files.\n\n")
-
-	fmt.Printf("%sUSAGE:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	fmt.Printf("  %s [OPTIONS] [<file/path/pattern>]\n", programName)
-	fmt.Printf("  %s                              # Process current directory recursively%s\n", programName, colorize(useColor, ColorDim))
-	fmt.Printf("  %s src/                         # Process src directory recursively%s\n", programName, colorize(useColor, ColorDim))
-	fmt.Printf("  %s \"*.go\"                       # Process all .go files in current directory%s\n", programName, colorize(useColor, ColorDim))
-	fmt.Printf("  %s \"./src/**/*.ts\"               # Process all .ts files recursively in src%s\n\n", programName, colorize(useColor, ColorReset))
-
-	fmt.Printf("%sOPTIONS:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-w, --write%s      Write changes to file instead of just logging\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-r, --recursive%s  Process directories recursively (default: true)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-c, --consecutive%s Remove consecutive single-line comments (default: false)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-e, --exclude%s    Comma-separated glob patterns to exclude (e.g., '*test.go,*.min.js')\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-i, --ignore-pattern%s Comma-separated patterns to ignore in comments (e.g., '@ts-ignore,@deprecated')\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-nc, --no-color%s  Disable colored output\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-nwl, --no-warn-large%s Disable warnings for large files (>500 LOC)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-h, --help%s       Show this help message\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-v, --version%s    Show version information\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s-m, --remove-single-multiline%s Remove single-line comments using multi-line patterns (e.g., /* comment */)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("\n")
-
-	fmt.Printf("%sSUPPORTED FILE TYPES:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	for _, lang := range SupportedLanguages {
-		fmt.Printf("  %s%s%s: %s (comment: %s%s%s)\n",
+	fmt.Fprintf(reportOutput, "%s%s%s - Comment Remover\n", colorize(useColor, ColorBold+ColorBlue), programName, colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "A performant CLI tool that safely removes single-line comments from source code files.\n\n")
+
+	fmt.Fprintf(reportOutput, "%sUSAGE:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s [OPTIONS] [<file/path/pattern>]\n", programName)
+	fmt.Fprintf(reportOutput, "  %s                              # Process current directory recursively%s\n", programName, colorize(useColor, ColorDim))
+	fmt.Fprintf(reportOutput, "  %s src/                         # Process src directory recursively%s\n", programName, colorize(useColor, ColorDim))
+	fmt.Fprintf(reportOutput, "  %s \"*.go\"                       # Process all .go files in current directory%s\n", programName, colorize(useColor, ColorDim))
+	fmt.Fprintf(reportOutput, "  %s \"./src/**/*.ts\"               # Process all .ts files recursively in src%s\n", programName, colorize(useColor, ColorDim))
+	fmt.Fprintf(reportOutput, "  %s - --stdin-filename src/a.ts   # Filter stdin to stdout, report on stderr%s\n\n", programName, colorize(useColor, ColorReset))
+
+	fmt.Fprintf(reportOutput, "%sOPTIONS:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-w, --write%s      Write changes to file instead of just logging\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--follow-symlinks%s Write through symlinks to their target instead of skipping them\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--check%s          Report removable comments without modifying files (exit 0: clean, 1: found, 2: errors)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--format%s <fmt>   Output format: %s%s%s (default: text)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), formatList(), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--diff%s           Show a unified diff of the changes for each file\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--patch%s <file>   Write a combined patch of all changes that %sgit apply%s accepts\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--stdin-filename%s <path> Name of the source read from stdin ('-'); picks its language\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--lang%s <key>     Language of the source read from stdin (e.g., typescript, go)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-r, --recursive%s  Process directories recursively (default: true)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-j, --jobs%s N     Number of files to process in parallel (default: GOMAXPROCS)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--process-minified%s Process minified files and single-line bundles (skipped by default)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-c, --consecutive%s Remove consecutive single-line comments (default: false)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-e, --exclude%s    Comma-separated glob patterns to exclude (e.g., '*test.go,*.min.js')\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-i, --ignore-pattern%s Pattern to ignore in comments; repeat for more (e.g., '-i @ts-ignore -i @deprecated')\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "                   Prefix with %sre:%s for a regular expression and %slang=<key>:%s to scope (e.g., %slang=go:re:^TODO\\(\\w+\\):%s)\n", colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-nc, --no-color%s  Disable colored output\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-nwl, --no-warn-large%s Disable warnings for large files (>500 LOC)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-h, --help%s       Show this help message\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-v, --version%s    Show version information\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--list-pragmas%s   List the built-in linter and compiler pragmas that are always preserved\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s-m, --remove-single-multiline%s Remove single-line comments using multi-line patterns (e.g., /* comment */)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--remove-blocks%s  Remove all block comments, including multi-line and inline ones\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--remove-if-zero%s Treat C/C++ #if 0 ... #endif blocks as comments and remove them\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--remove-docstrings%s Remove Python docstrings (kept by default)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--keep-doc-comments%s Keep documentation comments directly above declarations\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--remove-doc-comments%s Remove Rust doc comments (kept by default)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--strip-directives%s Also remove compiler directives and build tags (e.g., //go:build)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s--report-unused-directives%s Warn about commenter:disable/keep directives that protected nothing\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "\n")
+
+	fmt.Fprintf(reportOutput, "%sSUPPORTED FILE TYPES:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	for _, lang := range remover.SupportedLanguages() {
+		fmt.Fprintf(reportOutput, "  %s%s%s: %s (comment: %s%s%s)\n",
 			colorize(useColor, ColorCyan),
 			lang.Name,
 			colorize(useColor, ColorReset),
@@ -103,32 +135,57 @@ func showHelpMessage(useColor bool) {
 			colorize(useColor, ColorReset))
 	}
 
-	fmt.Printf("\n%sEXAMPLES:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	fmt.Printf("  %s%s                             # Process current directory recursively (default)\n", programName, "")
-	fmt.Printf("  %s example.go%s                  # Preview comment removal from single file\n", programName, "")
-	fmt.Printf("  %s %s-w%s example.ts%s               # Remove comments and save file\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s src/%s                        # Process src directory recursively\n", programName, "")
-	fmt.Printf("  %s %s-w -r%s project/%s              # Recursively process and save all files\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s \"*.go\"%s                      # Process all .go files in current directory\n", programName, "")
-	fmt.Printf("  %s \"./src/**/*.ts\"%s             # Process all .ts files recursively in src\n", programName, "")
-	fmt.Printf("  %s %s-w%s \"src/**/*.{ts,js}\"%s       # Process and save .ts/.js files in src\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s %s-e%s \"*test.go,*.min.js\"%s       # Exclude test files and minified files\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s %s-i%s \"@ts-ignore,@deprecated\"%s   # Ignore comments with specific patterns\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s %s-c%s file.ts%s                   # Remove consecutive comments too\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s %s-w -nc%s src/utils.js%s          # Save with no colors\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("  %s %s-m%s file.js%s                   # Remove single-line multi-line comments\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
-	fmt.Printf("\n")
-
-	fmt.Printf("%sWHAT GETS REMOVED:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	fmt.Printf("  %s✓%s Standalone comment lines (e.g., %s// This is a comment%s)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
-	fmt.Printf("  %s✓%s Inline comments (e.g., %scode(); // comment%s)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
-	fmt.Printf("  %s✓%s Multiple consecutive single-line comments\n\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
-	fmt.Printf("  %s✓%s Single-line multi-line comments (e.g., %s/* comment */%s) [with -m]\n\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
-
-	fmt.Printf("%sWHAT GETS PRESERVED:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
-	fmt.Printf("  %s×%s Multi-line comments (%s/* ... */%s)\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
-	fmt.Printf("  %s×%s Comments inside string literals (%s\"string with // comment\"%s)\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
-	fmt.Printf("  %s×%s Single-line comments inside multi-line comment blocks\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "\n%sEXAMPLES:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s%s                             # Process current directory recursively (default)\n", programName, "")
+	fmt.Fprintf(reportOutput, "  %s example.go%s                  # Preview comment removal from single file\n", programName, "")
+	fmt.Fprintf(reportOutput, "  %s %s-w%s example.ts%s               # Remove comments and save file\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s src/%s                        # Process src directory recursively\n", programName, "")
+	fmt.Fprintf(reportOutput, "  %s %s-w -r%s project/%s              # Recursively process and save all files\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s \"*.go\"%s                      # Process all .go files in current directory\n", programName, "")
+	fmt.Fprintf(reportOutput, "  %s \"./src/**/*.ts\"%s             # Process all .ts files recursively in src\n", programName, "")
+	fmt.Fprintf(reportOutput, "  %s %s-w%s \"src/**/*.{ts,js}\"%s       # Process and save .ts/.js files in src\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s %s-e%s \"*test.go,*.min.js\"%s       # Exclude test files and minified files\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s %s-i%s \"@ts-ignore,@deprecated\"%s   # Ignore comments with specific patterns\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s %s-c%s file.ts%s                   # Remove consecutive comments too\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s %s-w -nc%s src/utils.js%s          # Save with no colors\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "  %s %s-m%s file.js%s                   # Remove single-line multi-line comments\n", programName, colorize(useColor, ColorGreen), colorize(useColor, ColorReset), "")
+	fmt.Fprintf(reportOutput, "\n")
+
+	fmt.Fprintf(reportOutput, "%sWHAT GETS REMOVED:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s✓%s Standalone comment lines (e.g., %s// This is a comment%s)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s✓%s Inline comments (e.g., %scode(); // comment%s)\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s✓%s Multiple consecutive single-line comments\n\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s✓%s Single-line multi-line comments (e.g., %s/* comment */%s) [with -m]\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s✓%s All block comments, including %sfoo(/* a */ b)%s [with --remove-blocks]\n\n", colorize(useColor, ColorGreen), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+
+	fmt.Fprintf(reportOutput, "%sWHAT GETS PRESERVED:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Multi-line comments (%s/* ... */%s) [unless --remove-blocks]\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Comments inside string literals (%s\"string with // comment\"%s)\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Single-line comments inside multi-line comment blocks\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Regions marked with %s// commenter:disable%s ... %s// commenter:enable%s and lines marked %s// commenter:keep%s\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Linter and compiler pragmas (%seslint-disable%s, %s@ts-expect-error%s, ...) [see --list-pragmas]\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Compiler directives and build tags (%s//go:build%s, %s//nolint%s, cgo preambles) [unless --strip-directives]\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	fmt.Fprintf(reportOutput, "  %s×%s Doc comments above declarations (%s/** ... */%s, %s///%s) [with --keep-doc-comments; always for Rust unless --remove-doc-comments]\n", colorize(useColor, ColorRed), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset), colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+}
+
+func printPragmaCatalog(useColor bool, options ProcessingOptions) {
+	languages := remover.SupportedLanguages()
+	keys := slices.Sorted(maps.Keys(languages))
+
+	fmt.Fprintf(reportOutput, "%sPRESERVED PRAGMAS:%s\n", colorize(useColor, ColorBold+ColorYellow), colorize(useColor, ColorReset))
+	if options.DisablePragmas {
+		fmt.Fprintf(reportOutput, "  %s(built-in catalog disabled by config)%s\n", colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+	}
+	for _, key := range keys {
+		lang := remover.ApplyPragmaOptions(languages[key], options.removerOptions())
+		fmt.Fprintf(reportOutput, "  %s%s%s (%s)\n", colorize(useColor, ColorCyan), lang.Name, colorize(useColor, ColorReset), key)
+		if len(lang.Pragmas) == 0 {
+			fmt.Fprintf(reportOutput, "    %s(none)%s\n", colorize(useColor, ColorDim), colorize(useColor, ColorReset))
+		}
+		for _, pragma := range lang.Pragmas {
+			fmt.Fprintf(reportOutput, "    %s\n", pragma)
+		}
+	}
 }
 
 func printExecutionTime(useColor bool, duration time.Duration) {
@@ -149,7 +206,7 @@ func printExecutionTime(useColor bool, duration time.Duration) {
 		unit = "s"
 	}
 
-	fmt.Printf("\n%sExecution time:%s %s%s%s%s\n",
+	fmt.Fprintf(reportOutput, "\n%sExecution time:%s %s%s%s%s\n",
 		colorize(useColor, ColorDim),
 		colorize(useColor, ColorReset),
 		colorize(useColor, ColorBold),
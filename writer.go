@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var ErrSymlink = errors.New("refusing to write through symlink (use --follow-symlinks)")
+
+func isSymlink(filePath string) bool {
+	info, err := os.Lstat(filePath)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+func WriteFile(filePath string, content []byte, followSymlinks bool) error {
+	info, err := os.Lstat(filePath)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !followSymlinks {
+			return ErrSymlink
+		}
+		if filePath, err = filepath.EvalSymlinks(filePath); err != nil {
+			return err
+		}
+		if info, err = os.Stat(filePath); err != nil {
+			return err
+		}
+	}
+
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".commenter-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(content); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+		return err
+	}
+	preserveOwnership(tmpPath, info)
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return err
+	}
+	committed = true
+
+	syncDir(dir)
+	return nil
+}
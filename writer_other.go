@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+func preserveOwnership(string, os.FileInfo) {}
+
+func syncDir(string) {}